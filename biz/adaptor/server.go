@@ -3,38 +3,114 @@ package adaptor
 import (
 	"context"
 
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
 	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
 
+	"github.com/xh-polaris/meowchat-user/biz/application/job"
 	"github.com/xh-polaris/meowchat-user/biz/application/service"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/favorite"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
 )
 
 type UserServerImpl struct {
 	*config.Config
-	LikeService service.LikeService
-	UserService service.UserService
+	LikeService        service.LikeService
+	UserService        service.UserService
+	RewardService      service.RewardService
+	RelationService    service.RelationService
+	TrendingService    service.TrendingService
+	LeaderboardService service.LeaderboardService
+	ReconcileService   service.ReconcileService
+	FavoriteService    service.FavoriteService
+	ActivityService    service.ActivityService
+	Scheduler          *job.Scheduler
 }
 
 func (s *UserServerImpl) DoLike(ctx context.Context, req *user.DoLikeReq) (res *user.DoLikeResp, err error) {
 	return s.LikeService.DoLike(ctx, req)
 }
 
+func (s *UserServerImpl) Like(ctx context.Context, req *user.DoLikeReq) (*service.LikeResult, error) {
+	return s.LikeService.Like(ctx, req)
+}
+
+func (s *UserServerImpl) Unlike(ctx context.Context, req *user.DoLikeReq) (*service.LikeResult, error) {
+	return s.LikeService.Unlike(ctx, req)
+}
+
+func (s *UserServerImpl) React(ctx context.Context, req *user.DoLikeReq, reaction like.Reaction) (*service.LikeResult, error) {
+	return s.LikeService.React(ctx, req, reaction)
+}
+
+func (s *UserServerImpl) GetUserReaction(ctx context.Context, userId string, targetId string, likeType user.LikeType) (like.Reaction, error) {
+	return s.LikeService.GetUserReaction(ctx, userId, targetId, likeType)
+}
+
+func (s *UserServerImpl) GetReactionCounts(ctx context.Context, targetId string, likeType user.LikeType) (map[like.Reaction]int64, error) {
+	return s.LikeService.GetReactionCounts(ctx, targetId, likeType)
+}
+
+func (s *UserServerImpl) DeleteTargetLikes(ctx context.Context, targetId string, likeType user.LikeType) (int64, error) {
+	return s.LikeService.DeleteTargetLikes(ctx, targetId, likeType)
+}
+
 func (s *UserServerImpl) GetUserLike(ctx context.Context, req *user.GetUserLikedReq) (res *user.GetUserLikedResp, err error) {
 	return s.LikeService.GetUserLike(ctx, req)
 }
 
+func (s *UserServerImpl) GetUserLikedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error) {
+	return s.LikeService.GetUserLikedBatch(ctx, userId, targetIds, likeType)
+}
+
 func (s *UserServerImpl) GetTargetLikes(ctx context.Context, req *user.GetTargetLikesReq) (res *user.GetTargetLikesResp, err error) {
 	return s.LikeService.GetTargetLikes(ctx, req)
 }
 
+func (s *UserServerImpl) GetTargetLikesBatch(ctx context.Context, reqs []*user.GetTargetLikesReq) ([]int64, error) {
+	return s.LikeService.GetTargetLikesBatch(ctx, reqs)
+}
+
 func (s *UserServerImpl) GetUserLikes(ctx context.Context, req *user.GetUserLikesReq) (res *user.GetUserLikesResp, err error) {
 	return s.LikeService.GetUserLikes(ctx, req)
 }
 
+func (s *UserServerImpl) GetUserLikesByFilter(ctx context.Context, req *user.GetUserLikesReq, fopts *like.FilterOptions) (res *user.GetUserLikesResp, err error) {
+	return s.LikeService.GetUserLikesByFilter(ctx, req, fopts)
+}
+
 func (s *UserServerImpl) GetLikedUsers(ctx context.Context, req *user.GetLikedUsersReq) (res *user.GetLikedUsersResp, err error) {
 	return s.LikeService.GetLikedUsers(ctx, req)
 }
 
+func (s *UserServerImpl) GetLikedUsersByFilter(ctx context.Context, req *user.GetLikedUsersReq, fopts *like.FilterOptions) (res *user.GetLikedUsersResp, err error) {
+	return s.LikeService.GetLikedUsersByFilter(ctx, req, fopts)
+}
+
+func (s *UserServerImpl) GetFolloweesWhoLike(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*service.RelationList, error) {
+	return s.LikeService.GetFolloweesWhoLike(ctx, userId, targetId, likeType, paginationOptions)
+}
+
+func (s *UserServerImpl) GetCommonFollowing(ctx context.Context, userId string, otherId string, paginationOptions *basic.PaginationOptions) (*service.RelationList, error) {
+	return s.LikeService.GetCommonFollowing(ctx, userId, otherId, paginationOptions)
+}
+
+func (s *UserServerImpl) GetFolloweesLiked(ctx context.Context, userId string, targetId string, likeType user.LikeType, previewSize int64) (*service.RelationList, error) {
+	return s.LikeService.GetFolloweesLiked(ctx, userId, targetId, likeType, previewSize)
+}
+
+func (s *UserServerImpl) GetUserLikeTimeline(ctx context.Context, userId string, likeTypes []user.LikeType, paginationOptions *basic.PaginationOptions) (*service.LikeTimeline, error) {
+	return s.LikeService.GetUserLikeTimeline(ctx, userId, likeTypes, paginationOptions)
+}
+
+func (s *UserServerImpl) GetLikeHistory(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*service.LikeHistory, error) {
+	return s.LikeService.GetLikeHistory(ctx, userId, targetId, likeType, paginationOptions)
+}
+
+func (s *UserServerImpl) GetAssociatedLikes(ctx context.Context, associatedId string, likeType user.LikeType, topN int64) (*service.AssociatedLikes, error) {
+	return s.LikeService.GetAssociatedLikes(ctx, associatedId, likeType, topN)
+}
+
 func (s *UserServerImpl) GetUser(ctx context.Context, req *user.GetUserReq) (res *user.GetUserResp, err error) {
 	return s.UserService.GetUser(ctx, req)
 }
@@ -47,6 +123,98 @@ func (s *UserServerImpl) UpdateUser(ctx context.Context, req *user.UpdateUserReq
 	return s.UserService.UpdateUser(ctx, req)
 }
 
+func (s *UserServerImpl) UpdateTimezone(ctx context.Context, userId string, timezone string) error {
+	return s.UserService.UpdateTimezone(ctx, userId, timezone)
+}
+
 func (s *UserServerImpl) SearchUser(ctx context.Context, req *user.SearchUserReq) (res *user.SearchUserResp, err error) {
 	return s.UserService.SearchUser(ctx, req)
 }
+
+func (s *UserServerImpl) GetRewardHistory(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.RewardHistory, error) {
+	return s.RewardService.GetRewardHistory(ctx, userId, paginationOptions)
+}
+
+func (s *UserServerImpl) GetTodayReward(ctx context.Context, userId string) (int64, error) {
+	return s.RewardService.GetTodayReward(ctx, userId)
+}
+
+func (s *UserServerImpl) Follow(ctx context.Context, userId string, followeeId string) (*service.LikeResult, error) {
+	return s.RelationService.Follow(ctx, userId, followeeId)
+}
+
+func (s *UserServerImpl) Unfollow(ctx context.Context, userId string, followeeId string) (*service.LikeResult, error) {
+	return s.RelationService.Unfollow(ctx, userId, followeeId)
+}
+
+func (s *UserServerImpl) GetFollowers(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.RelationList, error) {
+	return s.RelationService.GetFollowers(ctx, userId, paginationOptions)
+}
+
+func (s *UserServerImpl) GetFollowing(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.RelationList, error) {
+	return s.RelationService.GetFollowing(ctx, userId, paginationOptions)
+}
+
+func (s *UserServerImpl) IsMutualFollow(ctx context.Context, userId string, otherId string) (bool, error) {
+	return s.RelationService.IsMutualFollow(ctx, userId, otherId)
+}
+
+func (s *UserServerImpl) GetRelationCount(ctx context.Context, userId string) (*service.RelationCount, error) {
+	return s.RelationService.GetRelationCount(ctx, userId)
+}
+
+func (s *UserServerImpl) GetTrending(ctx context.Context, likeType user.LikeType, window service.TrendingWindow, decay bool, offset int64, limit int64) ([]*service.TrendingTarget, error) {
+	return s.TrendingService.GetTrending(ctx, likeType, window, decay, offset, limit)
+}
+
+func (s *UserServerImpl) GetLeaderboard(ctx context.Context, period service.LeaderboardPeriod, offset int64, limit int64) ([]*service.LeaderboardEntry, error) {
+	return s.LeaderboardService.GetLeaderboard(ctx, period, offset, limit)
+}
+
+func (s *UserServerImpl) GetMyRank(ctx context.Context, userId string, period service.LeaderboardPeriod) (*service.LeaderboardEntry, error) {
+	return s.LeaderboardService.GetMyRank(ctx, userId, period)
+}
+
+func (s *UserServerImpl) ReconcileCounters(ctx context.Context) (*service.ReconcileReport, error) {
+	return s.ReconcileService.ReconcileCounters(ctx)
+}
+
+func (s *UserServerImpl) CreateFolder(ctx context.Context, userId string, name string) (*favorite.Folder, error) {
+	return s.FavoriteService.CreateFolder(ctx, userId, name)
+}
+
+func (s *UserServerImpl) RenameFolder(ctx context.Context, userId string, folderId string, name string) error {
+	return s.FavoriteService.RenameFolder(ctx, userId, folderId, name)
+}
+
+func (s *UserServerImpl) DeleteFolder(ctx context.Context, userId string, folderId string) error {
+	return s.FavoriteService.DeleteFolder(ctx, userId, folderId)
+}
+
+func (s *UserServerImpl) ListFolders(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.FolderList, error) {
+	return s.FavoriteService.ListFolders(ctx, userId, paginationOptions)
+}
+
+func (s *UserServerImpl) AddFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error) {
+	return s.FavoriteService.AddFavorite(ctx, userId, folderId, targetId, likeType)
+}
+
+func (s *UserServerImpl) RemoveFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error) {
+	return s.FavoriteService.RemoveFavorite(ctx, userId, folderId, targetId, likeType)
+}
+
+func (s *UserServerImpl) ListFavorites(ctx context.Context, userId string, folderId string, paginationOptions *basic.PaginationOptions) (*service.FavoriteList, error) {
+	return s.FavoriteService.ListFavorites(ctx, userId, folderId, paginationOptions)
+}
+
+func (s *UserServerImpl) GetSavedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error) {
+	return s.FavoriteService.GetSavedBatch(ctx, userId, targetIds, likeType)
+}
+
+func (s *UserServerImpl) GetUserActivities(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.ActivityList, error) {
+	return s.ActivityService.GetUserActivities(ctx, userId, paginationOptions)
+}
+
+func (s *UserServerImpl) GetFeed(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*service.ActivityList, error) {
+	return s.ActivityService.GetFeed(ctx, userId, paginationOptions)
+}
@@ -0,0 +1,109 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/threading"
+
+	"github.com/xh-polaris/meowchat-user/biz/application/service"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixJobLockKey = "jobLock:"
+
+// Scheduler 在服务进程内周期性执行后台任务，多实例部署时借助Redis锁保证每个周期只有一个实例执行
+type Scheduler struct {
+	Config             *config.Config
+	Redis              *redis.Redis
+	TrendingService    service.TrendingService
+	LeaderboardService service.LeaderboardService
+	ReconcileService   service.ReconcileService
+}
+
+var SchedulerSet = wire.NewSet(
+	wire.Struct(new(Scheduler), "*"),
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+func (s *Scheduler) jobs() []*job {
+	return []*job{
+		{
+			name:     "trending",
+			interval: time.Duration(s.Config.Trending.RefreshInterval) * time.Second,
+			run:      s.TrendingService.RefreshTrending,
+		},
+		{
+			name:     "leaderboard",
+			interval: time.Duration(s.Config.Leaderboard.RebuildInterval) * time.Second,
+			run:      s.LeaderboardService.RebuildLeaderboard,
+		},
+		{
+			name:     "reconcile",
+			interval: time.Duration(s.Config.Reconcile.Interval) * time.Second,
+			run: func(ctx context.Context) error {
+				_, err := s.ReconcileService.ReconcileCounters(ctx)
+				return err
+			},
+		},
+	}
+}
+
+// Start 启动所有后台任务，ctx取消后任务停止
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs() {
+		if j.interval <= 0 {
+			continue
+		}
+		j := j
+		threading.GoSafe(func() {
+			s.loop(ctx, j)
+		})
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	s.runOnce(ctx, j)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, j *job) {
+	// 锁不主动释放，过期前其他实例的同一周期都会跳过
+	lock := redis.NewRedisLock(s.Redis, prefixJobLockKey+j.name)
+	expire := int(j.interval / 2 / time.Second)
+	if expire < 1 {
+		expire = 1
+	}
+	lock.SetExpire(expire)
+	ok, err := lock.AcquireCtx(ctx)
+	if err != nil {
+		log.CtxError(ctx, "acquire job lock fail, job=%s, err=%v", j.name, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	start := time.Now()
+	if err = j.run(ctx); err != nil {
+		log.CtxError(ctx, "run job fail, job=%s, err=%v", j.name, err)
+		return
+	}
+	log.CtxInfo(ctx, "run job success, job=%s, cost=%v", j.name, time.Since(start))
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/activity"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+// ActivityService 用户动态，写入时只记录到用户自己的动态中，读取关注的人的动态时再合并
+type ActivityService interface {
+	RecordLike(ctx context.Context, data *like.Like)
+	RemoveLike(ctx context.Context, data *like.Like)
+	RemoveTarget(ctx context.Context, targetId string, likeType user.LikeType)
+	RecordProfileUpdate(ctx context.Context, userId string)
+	GetUserActivities(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*ActivityList, error)
+	GetFeed(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*ActivityList, error)
+}
+
+type ActivityList struct {
+	Activities []*activity.Activity
+	Total      int64
+	Token      string
+}
+
+type ActivityServiceImpl struct {
+	ActivityModel activity.IMongoMapper
+	LikeModel     like.IMongoMapper
+}
+
+var ActivitySet = wire.NewSet(
+	wire.Struct(new(ActivityServiceImpl), "*"),
+	wire.Bind(new(ActivityService), new(*ActivityServiceImpl)),
+)
+
+// RecordLike 记录点赞动态，关注记录为followed
+func (s *ActivityServiceImpl) RecordLike(ctx context.Context, data *like.Like) {
+	err := s.ActivityModel.Insert(ctx, &activity.Activity{
+		UserId:       data.UserId,
+		Kind:         likeActivityKind(data.TargetType),
+		TargetId:     data.TargetId,
+		TargetType:   data.TargetType,
+		AssociatedId: data.AssociatedId,
+	})
+	if err != nil {
+		log.CtxError(ctx, "record like activity fail, userId=%s, targetId=%s, err=%v", data.UserId, data.TargetId, err)
+	}
+}
+
+// RemoveLike 取消点赞后删除对应的动态
+func (s *ActivityServiceImpl) RemoveLike(ctx context.Context, data *like.Like) {
+	if err := s.ActivityModel.Delete(ctx, data.UserId, likeActivityKind(data.TargetType), data.TargetId, data.TargetType); err != nil {
+		log.CtxError(ctx, "remove like activity fail, userId=%s, targetId=%s, err=%v", data.UserId, data.TargetId, err)
+	}
+}
+
+// RemoveTarget 目标被删除后删除所有关于该目标及其关联目标的动态
+func (s *ActivityServiceImpl) RemoveTarget(ctx context.Context, targetId string, likeType user.LikeType) {
+	if _, err := s.ActivityModel.DeleteByTarget(ctx, targetId, int64(likeType)); err != nil {
+		log.CtxError(ctx, "remove target activities fail, targetId=%s, targetType=%d, err=%v", targetId, likeType, err)
+	}
+}
+
+func (s *ActivityServiceImpl) RecordProfileUpdate(ctx context.Context, userId string) {
+	err := s.ActivityModel.Insert(ctx, &activity.Activity{
+		UserId: userId,
+		Kind:   activity.KindProfileUpdated,
+	})
+	if err != nil {
+		log.CtxError(ctx, "record profile activity fail, userId=%s, err=%v", userId, err)
+	}
+}
+
+func (s *ActivityServiceImpl) GetUserActivities(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*ActivityList, error) {
+	return s.findActivities(ctx, []string{userId}, paginationOptions)
+}
+
+// GetFeed 合并用户最近关注的至多MaxFolloweeScan个用户的动态
+// 关注数超过MaxFolloweeScan时更早关注的用户的动态不会出现，Total也不包含这部分动态
+func (s *ActivityServiceImpl) GetFeed(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*ActivityList, error) {
+	followees, err := s.LikeModel.ListUserLikedTargets(ctx, userId, int64(user.LikeType_User), MaxFolloweeScan)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	if len(followees) == 0 {
+		return &ActivityList{Activities: make([]*activity.Activity, 0)}, nil
+	}
+	return s.findActivities(ctx, followees, paginationOptions)
+}
+
+func (s *ActivityServiceImpl) findActivities(ctx context.Context, userIds []string, paginationOptions *basic.PaginationOptions) (*ActivityList, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.ActivityModel.FindManyAndCount(ctx, userIds, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res := &ActivityList{
+		Activities: data,
+		Total:      total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	return res, nil
+}
+
+func likeActivityKind(targetType int64) string {
+	if targetType == int64(user.LikeType_User) {
+		return activity.KindFollowed
+	}
+	return activity.KindLiked
+}
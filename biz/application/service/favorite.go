@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/favorite"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+)
+
+// MaxFolderNameLength 收藏夹名称的最大字符数
+const MaxFolderNameLength = 32
+
+// FavoriteService 用户的收藏夹，与点赞相互独立，收藏不会发放小鱼干
+type FavoriteService interface {
+	CreateFolder(ctx context.Context, userId string, name string) (*favorite.Folder, error)
+	RenameFolder(ctx context.Context, userId string, folderId string, name string) error
+	DeleteFolder(ctx context.Context, userId string, folderId string) error
+	ListFolders(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*FolderList, error)
+	AddFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error)
+	RemoveFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error)
+	ListFavorites(ctx context.Context, userId string, folderId string, paginationOptions *basic.PaginationOptions) (*FavoriteList, error)
+	GetSavedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error)
+}
+
+type FolderList struct {
+	Folders []*favorite.Folder
+	Total   int64
+	Token   string
+}
+
+type FavoriteList struct {
+	Favorites []*favorite.Favorite
+	Total     int64
+	Token     string
+}
+
+type FavoriteServiceImpl struct {
+	FolderModel   favorite.IFolderMongoMapper
+	FavoriteModel favorite.IMongoMapper
+}
+
+var FavoriteSet = wire.NewSet(
+	wire.Struct(new(FavoriteServiceImpl), "*"),
+	wire.Bind(new(FavoriteService), new(*FavoriteServiceImpl)),
+)
+
+func (s *FavoriteServiceImpl) CreateFolder(ctx context.Context, userId string, name string) (*favorite.Folder, error) {
+	name, err := checkFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	folder := &favorite.Folder{
+		UserId: userId,
+		Name:   name,
+	}
+	err = s.FolderModel.Insert(ctx, folder)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, consts.ErrFolderExists
+	} else if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	return folder, nil
+}
+
+func (s *FavoriteServiceImpl) RenameFolder(ctx context.Context, userId string, folderId string, name string) error {
+	name, err := checkFolderName(name)
+	if err != nil {
+		return err
+	}
+	if _, err = s.getFolder(ctx, userId, folderId); err != nil {
+		return err
+	}
+	err = s.FolderModel.Rename(ctx, folderId, name)
+	if mongo.IsDuplicateKeyError(err) {
+		return consts.ErrFolderExists
+	} else if err != nil {
+		return consts.ErrDataBase
+	}
+	return nil
+}
+
+// DeleteFolder 删除收藏夹及其中的所有收藏
+func (s *FavoriteServiceImpl) DeleteFolder(ctx context.Context, userId string, folderId string) error {
+	if _, err := s.getFolder(ctx, userId, folderId); err != nil {
+		return err
+	}
+	if _, err := s.FavoriteModel.DeleteByFolder(ctx, folderId); err != nil {
+		return consts.ErrDataBase
+	}
+	if err := s.FolderModel.Delete(ctx, folderId); err != nil {
+		return consts.ErrDataBase
+	}
+	return nil
+}
+
+func (s *FavoriteServiceImpl) ListFolders(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*FolderList, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.FolderModel.FindManyAndCount(ctx, userId, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res := &FolderList{
+		Folders: data,
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	return res, nil
+}
+
+// AddFavorite 幂等地将目标加入收藏夹，返回本次调用是否新增了收藏
+func (s *FavoriteServiceImpl) AddFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error) {
+	if _, err := s.getFolder(ctx, userId, folderId); err != nil {
+		return false, err
+	}
+	err := s.FavoriteModel.Insert(ctx, &favorite.Favorite{
+		UserId:     userId,
+		FolderId:   folderId,
+		TargetId:   targetId,
+		TargetType: int64(likeType),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, consts.ErrDataBase
+	}
+	return true, nil
+}
+
+// RemoveFavorite 幂等地将目标移出收藏夹，返回本次调用是否移除了收藏
+func (s *FavoriteServiceImpl) RemoveFavorite(ctx context.Context, userId string, folderId string, targetId string, likeType user.LikeType) (bool, error) {
+	if _, err := s.getFolder(ctx, userId, folderId); err != nil {
+		return false, err
+	}
+	ok, err := s.FavoriteModel.Delete(ctx, folderId, targetId, int64(likeType))
+	if err != nil {
+		return false, consts.ErrDataBase
+	}
+	return ok, nil
+}
+
+func (s *FavoriteServiceImpl) ListFavorites(ctx context.Context, userId string, folderId string, paginationOptions *basic.PaginationOptions) (*FavoriteList, error) {
+	if _, err := s.getFolder(ctx, userId, folderId); err != nil {
+		return nil, err
+	}
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.FavoriteModel.FindManyAndCount(ctx, folderId, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res := &FavoriteList{
+		Favorites: data,
+		Total:     total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	return res, nil
+}
+
+// GetSavedBatch 批量查询目标是否被用户收藏到任一收藏夹
+func (s *FavoriteServiceImpl) GetSavedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error) {
+	if len(targetIds) > MaxBatchTargets {
+		return nil, consts.ErrTooManyTargets
+	}
+	res := make(map[string]bool, len(targetIds))
+	for _, id := range targetIds {
+		res[id] = false
+	}
+	if len(targetIds) == 0 {
+		return res, nil
+	}
+	saved, err := s.FavoriteModel.GetSavedTargets(ctx, userId, targetIds, int64(likeType))
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	for _, id := range saved {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// getFolder 获取用户自己的收藏夹，不属于该用户的收藏夹视为不存在
+func (s *FavoriteServiceImpl) getFolder(ctx context.Context, userId string, folderId string) (*favorite.Folder, error) {
+	folder, err := s.FolderModel.FindOne(ctx, folderId)
+	switch err {
+	case nil:
+	case consts.ErrNotFound, consts.ErrInvalidObjectId:
+		return nil, err
+	default:
+		return nil, consts.ErrDataBase
+	}
+	if folder.UserId != userId {
+		return nil, consts.ErrNotFound
+	}
+	return folder, nil
+}
+
+func checkFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxFolderNameLength {
+		return "", consts.ErrInvalidFolder
+	}
+	return name, nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixLeaderboardKey = "leaderboard:"
+
+type LeaderboardPeriod string
+
+const (
+	LeaderboardDaily   LeaderboardPeriod = "daily"
+	LeaderboardWeekly  LeaderboardPeriod = "weekly"
+	LeaderboardAllTime LeaderboardPeriod = "all"
+)
+
+var leaderboardPeriods = []LeaderboardPeriod{LeaderboardDaily, LeaderboardWeekly, LeaderboardAllTime}
+
+type LeaderboardService interface {
+	GetLeaderboard(ctx context.Context, period LeaderboardPeriod, offset int64, limit int64) ([]*LeaderboardEntry, error)
+	GetMyRank(ctx context.Context, userId string, period LeaderboardPeriod) (*LeaderboardEntry, error)
+	RecordLike(ctx context.Context, data *like.Like, delta int64)
+	RebuildLeaderboard(ctx context.Context) error
+}
+
+// LeaderboardEntry 排行榜中的一名用户，Rank从1开始，为0表示未上榜
+type LeaderboardEntry struct {
+	UserId string
+	Rank   int64
+	Likes  int64
+}
+
+type LeaderboardServiceImpl struct {
+	Config    *config.Config
+	LikeModel like.IMongoMapper
+	Redis     *redis.Redis
+	Clock     util.Clock
+}
+
+var LeaderboardSet = wire.NewSet(
+	wire.Struct(new(LeaderboardServiceImpl), "*"),
+	wire.Bind(new(LeaderboardService), new(*LeaderboardServiceImpl)),
+)
+
+// incrLeaderboardScript 增加用户在榜单中的获赞数，获赞数不大于0时移出榜单
+// 榜单尚未重建时忽略；重建时只保留了前Size名，此时不在榜单中的用户也忽略，其获赞数由下次重建统计，
+// 否则这些用户会以增量而不是实际获赞数重新出现在榜单中
+// KEYS[1] 榜单key, KEYS[2] 标记key，值为重建时榜单的最低分，榜单完整时为0
+// ARGV[1] 用户ID, ARGV[2] 增量
+var incrLeaderboardScript = redis.NewScript(`
+local cutoff = redis.call("GET", KEYS[2])
+if not cutoff then
+	return 0
+end
+local delta = tonumber(ARGV[2])
+if not redis.call("ZSCORE", KEYS[1], ARGV[1]) and (delta < 0 or tonumber(cutoff) > 0) then
+	return 0
+end
+local score = tonumber(redis.call("ZINCRBY", KEYS[1], delta, ARGV[1]))
+if score <= 0 then
+	redis.call("ZREM", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// GetLeaderboard 分页读取榜单，榜单只由后台任务重建，尚未重建时触发一次后台重建并返回空结果
+func (s *LeaderboardServiceImpl) GetLeaderboard(ctx context.Context, period LeaderboardPeriod, offset int64, limit int64) ([]*LeaderboardEntry, error) {
+	if !isLeaderboardPeriod(period) {
+		return nil, consts.ErrInvalidWindow
+	}
+	if limit <= 0 || limit > MaxBatchTargets {
+		limit = MaxBatchTargets
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	key, _, _ := s.bucket(period, s.Clock.Now())
+	pairs, err := s.Redis.ZrevrangeWithScoresByFloatCtx(ctx, key, offset, offset+limit-1)
+	if err != nil {
+		log.CtxError(ctx, "read leaderboard fail, key=%s, err=%v", key, err)
+		return nil, consts.ErrDataBase
+	}
+	res := make([]*LeaderboardEntry, 0, len(pairs))
+	for i, p := range pairs {
+		res = append(res, &LeaderboardEntry{UserId: p.Key, Rank: offset + int64(i) + 1, Likes: int64(p.Score)})
+	}
+	if len(pairs) == 0 && offset == 0 {
+		s.ensureBuilt(ctx, period, key)
+	}
+	return res, nil
+}
+
+// GetMyRank 查询用户在榜单中的名次和获赞数，不在榜单中的用户名次为0，获赞数从like集合统计
+func (s *LeaderboardServiceImpl) GetMyRank(ctx context.Context, userId string, period LeaderboardPeriod) (*LeaderboardEntry, error) {
+	if !isLeaderboardPeriod(period) {
+		return nil, consts.ErrInvalidWindow
+	}
+	now := s.Clock.Now()
+	key, _, _ := s.bucket(period, now)
+	res := &LeaderboardEntry{UserId: userId}
+	score, err := s.Redis.ZscoreByFloatCtx(ctx, key, userId)
+	switch err {
+	case nil:
+		rank, err := s.Redis.ZrevrankCtx(ctx, key, userId)
+		if err == nil {
+			res.Rank = rank + 1
+			res.Likes = int64(score)
+			return res, nil
+		}
+		if err != redis.Nil {
+			log.CtxError(ctx, "read leaderboard rank fail, key=%s, err=%v", key, err)
+		}
+	case redis.Nil:
+		s.ensureBuilt(ctx, period, key)
+	default:
+		log.CtxError(ctx, "read leaderboard score fail, key=%s, err=%v", key, err)
+	}
+
+	res.Likes, err = s.LikeModel.CountLikedUser(ctx, userId, s.periodStart(period, now))
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	return res, nil
+}
+
+// RecordLike 点赞或取消点赞后更新被点赞用户在各榜单中的获赞数，按点赞时间计入对应的日榜和周榜
+func (s *LeaderboardServiceImpl) RecordLike(ctx context.Context, data *like.Like, delta int64) {
+	if data.LikedUserId == "" || !isContentLike(data.TargetType) {
+		return
+	}
+	for _, period := range leaderboardPeriods {
+		key, _, ok := s.bucket(period, data.CreateAt)
+		if !ok {
+			continue
+		}
+		if _, err := s.Redis.ScriptRunCtx(ctx, incrLeaderboardScript, []string{key, key + ":ready"}, data.LikedUserId, delta); err != nil {
+			log.CtxError(ctx, "update leaderboard fail, key=%s, userId=%s, err=%v", key, data.LikedUserId, err)
+		}
+	}
+}
+
+// ensureBuilt 榜单尚未重建时触发一次后台重建
+func (s *LeaderboardServiceImpl) ensureBuilt(ctx context.Context, period LeaderboardPeriod, key string) {
+	ready, err := s.Redis.ExistsCtx(ctx, key+":ready")
+	if err != nil {
+		log.CtxError(ctx, "check leaderboard fail, key=%s, err=%v", key, err)
+		return
+	}
+	if !ready {
+		goRefresh(s.Redis, "leaderboardRefresh:"+key, func(ctx context.Context) error {
+			return s.rebuild(ctx, period)
+		})
+	}
+}
+
+// RebuildLeaderboard 从like集合重新统计当前周期的所有榜单
+func (s *LeaderboardServiceImpl) RebuildLeaderboard(ctx context.Context) error {
+	for _, period := range leaderboardPeriods {
+		if err := s.rebuild(ctx, period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuild 从like集合重新统计榜单的前Size名
+// 统计与写入之间产生的点赞会被写入覆盖，在下次重建前榜单会短暂少计这部分点赞
+func (s *LeaderboardServiceImpl) rebuild(ctx context.Context, period LeaderboardPeriod) error {
+	now := s.Clock.Now()
+	key, end, _ := s.bucket(period, now)
+	data, err := s.LikeModel.CountLikedUsers(ctx, s.periodStart(period, now), s.Config.Leaderboard.Size)
+	if err != nil {
+		return err
+	}
+
+	// 榜单在周期结束后多保留一天
+	var ttl int64
+	if !end.IsZero() {
+		ttl = int64(end.Add(24*time.Hour).Sub(now) / time.Second)
+	}
+	// 榜单被截断时记录最低分，未上榜用户的增量不再计入榜单
+	var cutoff int64
+	if int64(len(data)) >= s.Config.Leaderboard.Size && len(data) > 0 {
+		cutoff = data[len(data)-1].Count
+	}
+	args := make([]any, 0, 2*len(data)+2)
+	args = append(args, ttl, cutoff)
+	for _, d := range data {
+		args = append(args, d.Count, d.UserId)
+	}
+	if _, err = s.Redis.ScriptRunCtx(ctx, replaceZsetScript, []string{key, key + ":ready"}, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// periodStart 返回当前周期的开始时间，总榜为零值
+func (s *LeaderboardServiceImpl) periodStart(period LeaderboardPeriod, now time.Time) (start time.Time) {
+	switch period {
+	case LeaderboardDaily:
+		start, _ = util.DayRange(now, s.location())
+	case LeaderboardWeekly:
+		start, _ = util.WeekRange(now, s.location())
+	}
+	return
+}
+
+// bucket 返回t所在周期的榜单key和周期结束时间，总榜的结束时间为零值
+// key使用hash tag保证集群模式下榜单和标记key在同一个slot
+func (s *LeaderboardServiceImpl) bucket(period LeaderboardPeriod, t time.Time) (key string, end time.Time, ok bool) {
+	switch period {
+	case LeaderboardDaily:
+		start, end := util.DayRange(t, s.location())
+		return prefixLeaderboardKey + "{" + string(period) + ":" + start.Format("20060102") + "}", end, true
+	case LeaderboardWeekly:
+		start, end := util.WeekRange(t, s.location())
+		return prefixLeaderboardKey + "{" + string(period) + ":" + start.Format("20060102") + "}", end, true
+	case LeaderboardAllTime:
+		return prefixLeaderboardKey + "{" + string(period) + "}", time.Time{}, true
+	default:
+		return "", time.Time{}, false
+	}
+}
+
+// location 榜单的自然日与小鱼干奖励使用相同的时区
+func (s *LeaderboardServiceImpl) location() *time.Location {
+	loc, err := util.LoadLocation(s.Config.RewardTimezone)
+	if err != nil {
+		log.Error("load reward timezone fail, name=%s, err=%v", s.Config.RewardTimezone, err)
+		return time.Local
+	}
+	return loc
+}
+
+func isLeaderboardPeriod(period LeaderboardPeriod) bool {
+	for _, p := range leaderboardPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// isContentLike 关注不计入获赞数
+func isContentLike(targetType int64) bool {
+	return targetType != int64(user.LikeType_User)
+}
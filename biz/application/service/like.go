@@ -5,14 +5,20 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/xh-polaris/gopkg/pagination"
 	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
 	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
 	"github.com/zeromicro/go-zero/core/stores/redis"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/counter"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/limit"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
 
 	"github.com/google/wire"
 	"github.com/zeromicro/go-zero/core/stores/monc"
@@ -20,16 +26,96 @@ import (
 
 type LikeService interface {
 	DoLike(ctx context.Context, req *user.DoLikeReq) (res *user.DoLikeResp, err error)
+	Like(ctx context.Context, req *user.DoLikeReq) (*LikeResult, error)
+	Unlike(ctx context.Context, req *user.DoLikeReq) (*LikeResult, error)
+	React(ctx context.Context, req *user.DoLikeReq, reaction like.Reaction) (*LikeResult, error)
+	GetUserReaction(ctx context.Context, userId string, targetId string, likeType user.LikeType) (like.Reaction, error)
+	GetReactionCounts(ctx context.Context, targetId string, likeType user.LikeType) (map[like.Reaction]int64, error)
+	DeleteTargetLikes(ctx context.Context, targetId string, likeType user.LikeType) (int64, error)
 	GetUserLike(ctx context.Context, req *user.GetUserLikedReq) (res *user.GetUserLikedResp, err error)
+	GetUserLikedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error)
 	GetTargetLikes(ctx context.Context, req *user.GetTargetLikesReq) (res *user.GetTargetLikesResp, err error)
+	GetTargetLikesBatch(ctx context.Context, reqs []*user.GetTargetLikesReq) ([]int64, error)
 	GetUserLikes(ctx context.Context, req *user.GetUserLikesReq) (res *user.GetUserLikesResp, err error)
+	GetUserLikesByFilter(ctx context.Context, req *user.GetUserLikesReq, fopts *like.FilterOptions) (res *user.GetUserLikesResp, err error)
 	GetLikedUsers(ctx context.Context, req *user.GetLikedUsersReq) (res *user.GetLikedUsersResp, err error)
+	GetLikedUsersByFilter(ctx context.Context, req *user.GetLikedUsersReq, fopts *like.FilterOptions) (res *user.GetLikedUsersResp, err error)
+	GetFolloweesWhoLike(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*RelationList, error)
+	GetCommonFollowing(ctx context.Context, userId string, otherId string, paginationOptions *basic.PaginationOptions) (*RelationList, error)
+	GetFolloweesLiked(ctx context.Context, userId string, targetId string, likeType user.LikeType, previewSize int64) (*RelationList, error)
+	GetUserLikeTimeline(ctx context.Context, userId string, likeTypes []user.LikeType, paginationOptions *basic.PaginationOptions) (*LikeTimeline, error)
+	GetLikeHistory(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*LikeHistory, error)
+	GetAssociatedLikes(ctx context.Context, associatedId string, likeType user.LikeType, topN int64) (*AssociatedLikes, error)
 }
 
+// LikeResult 点赞或取消点赞后的状态，Changed表示本次调用是否修改了点赞状态，
+// Reaction为本次调用写入的表态，未写入时为空
+type LikeResult struct {
+	Liked        bool
+	Changed      bool
+	Reaction     like.Reaction
+	GetFish      bool
+	GetFishTimes int64
+}
+
+// LikeEvent 一次点赞或取消点赞
+type LikeEvent struct {
+	UserId     string
+	TargetId   string
+	TargetType int64
+	Liked      bool
+	Time       time.Time
+}
+
+// LikeHistory 点赞历史，按点赞记录倒序排列，每条记录展开为一次点赞及其取消，Total和分页均以点赞记录为单位
+type LikeHistory struct {
+	Events []*LikeEvent
+	Total  int64
+	Token  string
+}
+
+// LikeTimelineItem 用户点赞时间线中的一条点赞
+type LikeTimelineItem struct {
+	TargetId     string
+	TargetType   int64
+	AssociatedId string
+	Reaction     like.Reaction
+	LikeTime     time.Time
+}
+
+// LikeTimeline 用户跨类型的点赞时间线，按点赞时间倒序排列
+type LikeTimeline struct {
+	Items []*LikeTimelineItem
+	Total int64
+	Token string
+}
+
+// AssociatedLikes 关联到同一父级目标(如帖子下的评论)的点赞统计
+type AssociatedLikes struct {
+	Total    int64
+	Children []*like.TargetCount
+}
+
+const (
+	// MaxBatchTargets 批量查询时单次允许的最大目标数
+	MaxBatchTargets = 100
+	// MaxFolloweeScan 计算关注的人点赞情况和关注动态时最多取最近关注的用户数，关注更多用户时结果只覆盖这部分用户
+	MaxFolloweeScan = 5000
+	// MaxLikedPreview 关注的人点赞预览最多返回的用户数
+	MaxLikedPreview = 20
+	// MaxDeleteBatch 删除目标时每批取消的点赞数
+	MaxDeleteBatch = 500
+)
+
 type LikeServiceImpl struct {
-	Config    *config.Config
-	LikeModel like.IMongoMapper
-	Redis     *redis.Redis
+	Config             *config.Config
+	LikeModel          like.IMongoMapper
+	CounterModel       counter.IMongoMapper
+	Redis              *redis.Redis
+	RewardService      RewardService
+	LeaderboardService LeaderboardService
+	ActivityService    ActivityService
+	TrendingService    TrendingService
 }
 
 var LikeSet = wire.NewSet(
@@ -38,112 +124,263 @@ var LikeSet = wire.NewSet(
 )
 
 func (s *LikeServiceImpl) DoLike(ctx context.Context, req *user.DoLikeReq) (res *user.DoLikeResp, err error) {
-	// 判断是否点过赞
-	res = new(user.DoLikeResp)
+	if err = s.checkRateLimit(ctx, req.UserId); err != nil {
+		return &user.DoLikeResp{}, err
+	}
 
+	// 判断是否点过赞
 	data := &user.GetUserLikedReq{
 		UserId:   req.UserId,
 		TargetId: req.TargetId,
 		Type:     req.Type,
 	}
 	response, _ := s.GetUserLike(ctx, data)
-	switch response.Liked {
-	case false:
-		// 插入数据
-		likeModel := s.LikeModel
-		alike := &like.Like{
-			UserId:       req.UserId,
-			TargetId:     req.TargetId,
-			TargetType:   int64(req.Type),
-			AssociatedId: req.AssociatedId,
-		}
-		err = likeModel.Insert(ctx, alike)
-		if err != nil {
-			return &user.DoLikeResp{}, consts.ErrDataBase
-		}
-		res.Liked = true
+	var r *LikeResult
+	if response.Liked {
+		r, err = s.unlike(ctx, req)
+	} else {
+		r, err = s.like(ctx, req, like.ReactionLike)
+	}
+	if err != nil {
+		return &user.DoLikeResp{}, err
+	}
+	return &user.DoLikeResp{
+		GetFish:      r.GetFish,
+		GetFishTimes: r.GetFishTimes,
+		Liked:        r.Liked,
+	}, nil
+}
+
+// Like 幂等点赞，已点赞时不做任何修改
+func (s *LikeServiceImpl) Like(ctx context.Context, req *user.DoLikeReq) (*LikeResult, error) {
+	if err := s.checkRateLimit(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	return s.like(ctx, req, like.ReactionLike)
+}
+
+// Unlike 幂等取消点赞，未点赞时不做任何修改
+func (s *LikeServiceImpl) Unlike(ctx context.Context, req *user.DoLikeReq) (*LikeResult, error) {
+	if err := s.checkRateLimit(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	return s.unlike(ctx, req)
+}
+
+// React 以指定表态点赞，已有其他表态时切换为该表态，切换表态不影响点赞数和小鱼干奖励
+func (s *LikeServiceImpl) React(ctx context.Context, req *user.DoLikeReq, reaction like.Reaction) (*LikeResult, error) {
+	if !like.IsValidReaction(reaction) {
+		return nil, consts.ErrInvalidReaction
+	}
+	if err := s.checkRateLimit(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	alike, err := s.LikeModel.FindUserLike(ctx, req.UserId, req.TargetId, int64(req.Type))
+	switch err {
+	case nil:
+	case monc.ErrNotFound:
+		return s.like(ctx, req, reaction)
+	default:
+		return nil, consts.ErrDataBase
+	}
+	if alike.GetReaction() == reaction {
+		return &LikeResult{Liked: true}, nil
+	}
+	if err = s.LikeModel.UpdateReaction(ctx, alike.ID.Hex(), reaction); err != nil {
+		return nil, consts.ErrDataBase
+	}
+	return &LikeResult{Liked: true, Changed: true, Reaction: reaction}, nil
+}
 
-		if req.Type == user.LikeType_User {
-			res.GetFish = false
-			return res, nil
+// GetUserReaction 返回用户对目标的表态，未点赞时返回空
+func (s *LikeServiceImpl) GetUserReaction(ctx context.Context, userId string, targetId string, likeType user.LikeType) (like.Reaction, error) {
+	alike, err := s.LikeModel.FindUserLike(ctx, userId, targetId, int64(likeType))
+	switch err {
+	case nil:
+		return alike.GetReaction(), nil
+	case monc.ErrNotFound:
+		return "", nil
+	default:
+		return "", consts.ErrDataBase
+	}
+}
+
+// GetReactionCounts 按表态分别统计目标的点赞数，没有点赞的表态计数为0
+func (s *LikeServiceImpl) GetReactionCounts(ctx context.Context, targetId string, likeType user.LikeType) (map[like.Reaction]int64, error) {
+	data, err := s.LikeModel.CountReactions(ctx, targetId, int64(likeType))
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res := make(map[like.Reaction]int64, len(like.Reactions))
+	for _, r := range like.Reactions {
+		res[r] = 0
+	}
+	for _, d := range data {
+		res[d.Reaction] = d.Count
+	}
+	return res, nil
+}
+
+// DeleteTargetLikes 目标被删除时取消其所有点赞以及关联到它的点赞，返回取消的数量
+// 受影响目标的计数器会被删除，下次读取时重新统计；取消点赞不会收回已发放的小鱼干
+func (s *LikeServiceImpl) DeleteTargetLikes(ctx context.Context, targetId string, likeType user.LikeType) (int64, error) {
+	// 目标本身以及被取消过点赞的关联目标都从热门榜单中移除
+	removed := []like.Target{{TargetId: targetId, TargetType: int64(likeType)}}
+	seen := map[like.Target]bool{removed[0]: true}
+	// 中途失败时部分点赞已被取消，无论如何都不再展示被删除的目标
+	defer func() {
+		s.ActivityService.RemoveTarget(ctx, targetId, likeType)
+		for _, t := range removed {
+			s.TrendingService.RemoveTarget(ctx, t.TargetId, user.LikeType(t.TargetType))
 		}
+	}()
 
-		t, err := s.Redis.GetCtx(ctx, "likeTimes"+req.UserId)
+	var total int64
+	for {
+		data, err := s.LikeModel.FindTargetLikes(ctx, targetId, int64(likeType), MaxDeleteBatch)
 		if err != nil {
-			return &user.DoLikeResp{GetFish: false, Liked: true}, nil
+			return total, consts.ErrDataBase
 		}
-		r, err := s.Redis.GetCtx(ctx, "likeDates"+req.UserId)
-		if err != nil {
-			return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-		} else if r == "" {
-			res.GetFish = true
-			res.GetFishTimes = 1
-			err = s.Redis.SetexCtx(ctx, "likeTimes"+req.UserId, "1", 86400)
-			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-			}
-			err = s.Redis.SetexCtx(ctx, "likeDates"+req.UserId, strconv.FormatInt(time.Now().Unix(), 10), 86400)
-			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-			}
-		} else {
-			times, err := strconv.ParseInt(t, 10, 64)
-			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-			}
-			res.GetFishTimes = times + 1
-			date, err := strconv.ParseInt(r, 10, 64)
-			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
+		if len(data) == 0 {
+			return total, nil
+		}
+
+		targets := make([]like.Target, 0)
+		batch := make(map[like.Target]bool)
+		for _, alike := range data {
+			t := like.Target{TargetId: alike.TargetId, TargetType: alike.TargetType}
+			if !batch[t] {
+				batch[t] = true
+				targets = append(targets, t)
 			}
-			lastTime := time.Unix(date, 0)
-			err = s.Redis.SetexCtx(ctx, "likeTimes"+req.UserId, strconv.FormatInt(times+1, 10), 86400)
-			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
+			if !seen[t] {
+				seen[t] = true
+				removed = append(removed, t)
 			}
-			err = s.Redis.SetexCtx(ctx, "likeDates"+req.UserId, strconv.FormatInt(time.Now().Unix(), 10), 86400)
+		}
+		// 逐条取消，只为本次实际取消的点赞更新榜单，并发取消的点赞已由取消方更新
+		var deleteErr error
+		for _, alike := range data {
+			ok, err := s.LikeModel.SoftDelete(ctx, alike.ID.Hex())
 			if err != nil {
-				return &user.DoLikeResp{GetFish: false, Liked: true}, nil
+				deleteErr = err
+				break
 			}
-			if lastTime.Day() == time.Now().Day() && lastTime.Month() == time.Now().Month() && lastTime.Year() == time.Now().Year() {
-				err = s.Redis.SetexCtx(ctx, "likeTimes"+req.UserId, strconv.FormatInt(times+1, 10), 86400)
-				if err != nil {
-					return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-				}
-				if times >= s.Config.LikeTimes {
-					res.GetFish = false
-				} else {
-					res.GetFish = true
-				}
-			} else {
-				err = s.Redis.SetexCtx(ctx, "likeTimes"+req.UserId, "1", 86400)
-				if err != nil {
-					return &user.DoLikeResp{GetFish: false, Liked: true}, nil
-				}
-				res.GetFish = true
-				res.GetFishTimes = 1
+			if ok {
+				total++
+				s.LeaderboardService.RecordLike(ctx, alike, -1)
 			}
 		}
-
-		return res, nil
-	case true:
-		likeModel := s.LikeModel
-		ID, err := likeModel.GetId(ctx, req.UserId, req.TargetId, int64(req.Type))
-		if err != nil {
-			return &user.DoLikeResp{}, consts.ErrDataBase
+		// 即使中途失败也删除计数器，避免计数器继续包含已取消的点赞
+		if err = s.CounterModel.DeleteMany(ctx, targets); err != nil {
+			log.CtxError(ctx, "delete like counters fail, targetId=%s, targetType=%d, err=%v", targetId, likeType, err)
 		}
-		err = likeModel.Delete(ctx, ID)
-		if err == nil {
-			return &user.DoLikeResp{GetFish: false}, nil
-		} else {
-			return &user.DoLikeResp{}, consts.ErrDataBase
+		if deleteErr != nil {
+			return total, consts.ErrDataBase
 		}
+	}
+}
+
+func (s *LikeServiceImpl) like(ctx context.Context, req *user.DoLikeReq, reaction like.Reaction) (*LikeResult, error) {
+	err := s.LikeModel.GetUserLike(ctx, req.UserId, req.TargetId, int64(req.Type))
+	switch err {
+	case nil:
+		return &LikeResult{Liked: true}, nil
+	case monc.ErrNotFound:
 	default:
-		return &user.DoLikeResp{GetFish: false}, nil
+		return nil, consts.ErrDataBase
+	}
+	if err = s.checkCooldown(ctx, req); err != nil {
+		return nil, err
+	}
+
+	alike := &like.Like{
+		UserId:       req.UserId,
+		TargetId:     req.TargetId,
+		TargetType:   int64(req.Type),
+		AssociatedId: req.AssociatedId,
+		LikedUserId:  req.LikedUserId,
+		Reaction:     reaction,
+	}
+	err = s.LikeModel.Insert(ctx, alike)
+	// 处理并发冲突，唯一索引冲突说明已经点过赞
+	if mongo.IsDuplicateKeyError(err) {
+		return &LikeResult{Liked: true}, nil
+	} else if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	s.addLikeFilter(ctx, req.UserId, req.TargetId, int64(req.Type))
+	s.increaseTargetLikes(ctx, req.TargetId, int64(req.Type), 1)
+	s.LeaderboardService.RecordLike(ctx, alike, 1)
+	s.ActivityService.RecordLike(ctx, alike)
+
+	res := &LikeResult{Liked: true, Changed: true, Reaction: reaction}
+	res.GetFish, res.GetFishTimes = s.RewardService.RewardLike(ctx, req, alike.ID.Hex())
+	return res, nil
+}
+
+func (s *LikeServiceImpl) unlike(ctx context.Context, req *user.DoLikeReq) (*LikeResult, error) {
+	alike, err := s.LikeModel.FindUserLike(ctx, req.UserId, req.TargetId, int64(req.Type))
+	switch err {
+	case nil:
+	case monc.ErrNotFound:
+		return &LikeResult{Liked: false}, nil
+	default:
+		return nil, consts.ErrDataBase
+	}
+	if err = s.checkCooldown(ctx, req); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.LikeModel.SoftDelete(ctx, alike.ID.Hex())
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	if !ok {
+		// 并发的取消点赞已经生效，计数等只由生效的一次更新
+		return &LikeResult{Liked: false}, nil
 	}
+	s.increaseTargetLikes(ctx, req.TargetId, int64(req.Type), -1)
+	s.LeaderboardService.RecordLike(ctx, alike, -1)
+	s.ActivityService.RemoveLike(ctx, alike)
+	return &LikeResult{Liked: false, Changed: true}, nil
+}
+
+// checkRateLimit 限制每个用户在滑动窗口内的点赞请求次数，Redis异常时放行
+func (s *LikeServiceImpl) checkRateLimit(ctx context.Context, userId string) error {
+	l := limit.NewSlidingWindowLimiter(s.Redis, "likeRate:", time.Duration(s.Config.LikeRateWindow)*time.Second, s.Config.LikeRateLimit)
+	ok, err := l.AllowCtx(ctx, userId)
+	if err != nil {
+		log.CtxError(ctx, "check like rate limit fail, userId=%s, err=%v", userId, err)
+		return nil
+	}
+	if !ok {
+		return consts.ErrLikeRateLimit
+	}
+	return nil
+}
+
+// checkCooldown 防止短时间内反复切换同一目标的点赞状态，Redis异常时放行
+func (s *LikeServiceImpl) checkCooldown(ctx context.Context, req *user.DoLikeReq) error {
+	if s.Config.LikeCooldown <= 0 {
+		return nil
+	}
+	key := "likeCooldown:" + req.UserId + ":" + strconv.FormatInt(int64(req.Type), 10) + ":" + req.TargetId
+	ok, err := s.Redis.SetnxExCtx(ctx, key, "1", int(s.Config.LikeCooldown))
+	if err != nil {
+		log.CtxError(ctx, "check like cooldown fail, key=%s, err=%v", key, err)
+		return nil
+	}
+	if !ok {
+		return consts.ErrLikeCooldown
+	}
+	return nil
 }
 
 func (s *LikeServiceImpl) GetUserLike(ctx context.Context, req *user.GetUserLikedReq) (res *user.GetUserLikedResp, err error) {
+	if !s.mayHaveLiked(ctx, req.UserId, req.TargetId, int64(req.Type)) {
+		return &user.GetUserLikedResp{Liked: false}, nil
+	}
 	likeModel := s.LikeModel
 	err = likeModel.GetUserLike(ctx, req.UserId, req.TargetId, int64(req.Type))
 	switch err {
@@ -155,20 +392,173 @@ func (s *LikeServiceImpl) GetUserLike(ctx context.Context, req *user.GetUserLike
 	return &user.GetUserLikedResp{}, nil
 }
 
+func (s *LikeServiceImpl) GetUserLikedBatch(ctx context.Context, userId string, targetIds []string, likeType user.LikeType) (map[string]bool, error) {
+	if len(targetIds) > MaxBatchTargets {
+		return nil, consts.ErrTooManyTargets
+	}
+	res := make(map[string]bool, len(targetIds))
+	for _, id := range targetIds {
+		res[id] = false
+	}
+	if len(targetIds) == 0 {
+		return res, nil
+	}
+	liked, err := s.LikeModel.GetUserLikedTargets(ctx, userId, targetIds, int64(likeType))
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	for _, id := range liked {
+		res[id] = true
+	}
+	return res, nil
+}
+
 func (s *LikeServiceImpl) GetTargetLikes(ctx context.Context, req *user.GetTargetLikesReq) (res *user.GetTargetLikesResp, err error) {
-	likeModel := s.LikeModel
-	count, err := likeModel.GetTargetLikes(ctx, req.TargetId, int64(req.Type))
+	count, err := s.countTargetLikes(ctx, req.TargetId, int64(req.Type))
 	if err != nil {
 		return &user.GetTargetLikesResp{}, consts.ErrDataBase
 	} else {
-		return &user.GetTargetLikesResp{Count: int64(len(count))}, nil
+		return &user.GetTargetLikesResp{Count: count}, nil
+	}
+}
+
+// GetTargetLikesBatch 按请求顺序返回每个目标的点赞数
+func (s *LikeServiceImpl) GetTargetLikesBatch(ctx context.Context, reqs []*user.GetTargetLikesReq) ([]int64, error) {
+	if len(reqs) > MaxBatchTargets {
+		return nil, consts.ErrTooManyTargets
+	}
+	counts := make(map[like.Target]int64, len(reqs))
+	targets := make([]like.Target, 0, len(reqs))
+	for _, req := range reqs {
+		t := like.Target{TargetId: req.TargetId, TargetType: int64(req.Type)}
+		if _, ok := counts[t]; !ok {
+			counts[t] = -1
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) > 0 {
+		counters, err := s.CounterModel.FindMany(ctx, targets)
+		if err != nil {
+			return nil, consts.ErrDataBase
+		}
+		for _, c := range counters {
+			counts[like.Target{TargetId: c.TargetId, TargetType: c.TargetType}] = c.Count
+		}
+	}
+
+	// 计数器缺失的目标通过一次聚合重新统计
+	missing := make([]like.Target, 0)
+	for _, t := range targets {
+		if counts[t] < 0 {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.initCounters(ctx, missing, counts); err != nil {
+			return nil, consts.ErrDataBase
+		}
+	}
+
+	res := make([]int64, 0, len(reqs))
+	for _, req := range reqs {
+		res = append(res, counts[like.Target{TargetId: req.TargetId, TargetType: int64(req.Type)}])
+	}
+	return res, nil
+}
+
+func (s *LikeServiceImpl) countTargetLikes(ctx context.Context, targetId string, targetType int64) (int64, error) {
+	c, err := s.CounterModel.FindOne(ctx, targetId, targetType)
+	switch err {
+	case nil:
+		return c.Count, nil
+	case consts.ErrNotFound:
+		// 计数器缺失时从like集合重新统计
+		t := like.Target{TargetId: targetId, TargetType: targetType}
+		counts := make(map[like.Target]int64, 1)
+		if err = s.initCounters(ctx, []like.Target{t}, counts); err != nil {
+			return 0, err
+		}
+		return counts[t], nil
+	default:
+		return 0, err
+	}
+}
+
+// initCounters 从like集合统计计数器缺失的目标的点赞数并初始化计数器，结果写入counts
+// 计数器不存在时点赞无法累加，统计与初始化之间产生的点赞会丢失，因此初始化后再统计一次，
+// 计数器仍为初始值时修正为新的统计结果，之后仍然存在的偏差由校对任务修正
+func (s *LikeServiceImpl) initCounters(ctx context.Context, missing []like.Target, counts map[like.Target]int64) error {
+	if err := s.countTargets(ctx, missing, counts); err != nil {
+		return err
+	}
+	for _, t := range missing {
+		if err := s.CounterModel.Init(ctx, t.TargetId, t.TargetType, counts[t]); err != nil {
+			log.CtxError(ctx, "init like counter fail, targetId=%s, targetType=%d, err=%v", t.TargetId, t.TargetType, err)
+		}
+	}
+
+	recounts := make(map[like.Target]int64, len(missing))
+	if err := s.countTargets(ctx, missing, recounts); err != nil {
+		log.CtxError(ctx, "recount target likes fail, err=%v", err)
+		return nil
+	}
+	for _, t := range missing {
+		if recounts[t] == counts[t] {
+			continue
+		}
+		if _, err := s.CounterModel.CompareAndSet(ctx, t.TargetId, t.TargetType, counts[t], recounts[t]); err != nil {
+			log.CtxError(ctx, "correct like counter fail, targetId=%s, targetType=%d, err=%v", t.TargetId, t.TargetType, err)
+		}
+		counts[t] = recounts[t]
+	}
+	return nil
+}
+
+// countTargets 从like集合统计目标的点赞数，结果写入counts
+func (s *LikeServiceImpl) countTargets(ctx context.Context, targets []like.Target, counts map[like.Target]int64) error {
+	data, err := s.LikeModel.CountTargets(ctx, targets)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		counts[t] = 0
+	}
+	for _, d := range data {
+		counts[d.Target] = d.Count
+	}
+	return nil
+}
+
+func (s *LikeServiceImpl) increaseTargetLikes(ctx context.Context, targetId string, targetType int64, delta int64) {
+	if err := s.CounterModel.Increase(ctx, targetId, targetType, delta); err != nil {
+		log.CtxError(ctx, "increase like counter fail, targetId=%s, targetType=%d, err=%v", targetId, targetType, err)
 	}
 }
 
 func (s *LikeServiceImpl) GetUserLikes(ctx context.Context, req *user.GetUserLikesReq) (res *user.GetUserLikesResp, err error) {
+	targetType := int32(req.Type)
+	return s.GetUserLikesByFilter(ctx, req, &like.FilterOptions{
+		OnlyUserId:     &req.UserId,
+		OnlyTargetType: &targetType,
+	})
+}
+
+// GetUserLikesByFilter 在fopts的基础上查询用户的点赞，req中的UserId和Type不为空时覆盖fopts中的对应条件
+func (s *LikeServiceImpl) GetUserLikesByFilter(ctx context.Context, req *user.GetUserLikesReq, fopts *like.FilterOptions) (res *user.GetUserLikesResp, err error) {
 	p := util.ParsePagination(req.PaginationOptions)
+	filter := new(like.FilterOptions)
+	if fopts != nil {
+		*filter = *fopts
+	}
+	if req.UserId != "" {
+		filter.OnlyUserId = &req.UserId
+	}
+	if req.Type != user.LikeType_Unknown {
+		targetType := int32(req.Type)
+		filter.OnlyTargetType = &targetType
+	}
 
-	data, total, err := s.LikeModel.GetUserLikes(ctx, req.UserId, int64(req.Type), p, mongop.IdCursorType)
+	data, total, err := s.LikeModel.FindManyAndCount(ctx, filter, p, mongop.IdCursorType)
 	if err != nil {
 		return nil, err
 	}
@@ -189,12 +579,29 @@ func (s *LikeServiceImpl) GetUserLikes(ctx context.Context, req *user.GetUserLik
 }
 
 func (s *LikeServiceImpl) GetLikedUsers(ctx context.Context, req *user.GetLikedUsersReq) (res *user.GetLikedUsersResp, err error) {
+	return s.GetLikedUsersByFilter(ctx, req, &like.FilterOptions{
+		OnlyTargetId:   &req.TargetId,
+		OnlyTargetType: (*int32)(&req.Type),
+	})
+}
+
+// GetLikedUsersByFilter 在fopts的基础上查询点赞的用户，req中的TargetId和Type不为空时覆盖fopts中的对应条件
+func (s *LikeServiceImpl) GetLikedUsersByFilter(ctx context.Context, req *user.GetLikedUsersReq, fopts *like.FilterOptions) (res *user.GetLikedUsersResp, err error) {
 	res = new(user.GetLikedUsersResp)
 
 	p := util.ParsePagination(req.PaginationOptions)
-	filter := &like.FilterOptions{
-		OnlyTargetId:   &req.TargetId,
-		OnlyTargetType: (*int32)(&req.Type),
+	// 未指定Limit时使用默认分页大小
+	p.EnsureSafe()
+	filter := new(like.FilterOptions)
+	if fopts != nil {
+		*filter = *fopts
+	}
+	if req.TargetId != "" {
+		filter.OnlyTargetId = &req.TargetId
+	}
+	if req.Type != user.LikeType_Unknown {
+		targetType := int32(req.Type)
+		filter.OnlyTargetType = &targetType
 	}
 
 	if *p.Limit == 0 {
@@ -220,3 +627,171 @@ func (s *LikeServiceImpl) GetLikedUsers(ctx context.Context, req *user.GetLikedU
 	}
 	return res, nil
 }
+
+// GetFolloweesWhoLike 查询userId关注的用户中点赞过目标的用户，目标为用户时即关注的人中谁也关注了他
+func (s *LikeServiceImpl) GetFolloweesWhoLike(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*RelationList, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.LikeModel.FindFolloweesWhoLike(ctx, userId, targetId, int64(likeType), p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &RelationList{
+		UserIds: make([]string, 0, len(data)),
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		res.UserIds = append(res.UserIds, alike.TargetId)
+	}
+	return res, nil
+}
+
+// GetCommonFollowing 查询userId和otherId共同关注的用户
+func (s *LikeServiceImpl) GetCommonFollowing(ctx context.Context, userId string, otherId string, paginationOptions *basic.PaginationOptions) (*RelationList, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.LikeModel.FindCommonLikes(ctx, userId, otherId, int64(user.LikeType_User), p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &RelationList{
+		UserIds: make([]string, 0, len(data)),
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		res.UserIds = append(res.UserIds, alike.TargetId)
+	}
+	return res, nil
+}
+
+// GetFolloweesLiked 返回userId关注的人中点赞过目标的前previewSize个用户及总数
+// 只统计最近关注的MaxFolloweeScan个用户，关注数超过该值时总数是近似值，可能小于实际人数
+func (s *LikeServiceImpl) GetFolloweesLiked(ctx context.Context, userId string, targetId string, likeType user.LikeType, previewSize int64) (*RelationList, error) {
+	if previewSize <= 0 || previewSize > MaxLikedPreview {
+		previewSize = MaxLikedPreview
+	}
+	followees, err := s.LikeModel.ListUserLikedTargets(ctx, userId, int64(user.LikeType_User), MaxFolloweeScan)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res := &RelationList{UserIds: []string{}}
+	if len(followees) == 0 {
+		return res, nil
+	}
+
+	targetType := int32(likeType)
+	filter := &like.FilterOptions{
+		OnlyTargetId:   &targetId,
+		OnlyTargetType: &targetType,
+		OnlyUserIds:    followees,
+	}
+	data, total, err := s.LikeModel.FindManyAndCount(ctx, filter, &pagination.PaginationOptions{Limit: &previewSize}, mongop.IdCursorType)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	res.Total = total
+	for _, alike := range data {
+		res.UserIds = append(res.UserIds, alike.UserId)
+	}
+	return res, nil
+}
+
+// GetUserLikeTimeline 分页返回用户在likeTypes中的点赞，likeTypes为空时包含所有类型
+func (s *LikeServiceImpl) GetUserLikeTimeline(ctx context.Context, userId string, likeTypes []user.LikeType, paginationOptions *basic.PaginationOptions) (*LikeTimeline, error) {
+	p := util.ParsePagination(paginationOptions)
+	filter := &like.FilterOptions{
+		OnlyUserId: &userId,
+	}
+	for _, t := range likeTypes {
+		filter.OnlyTargetTypes = append(filter.OnlyTargetTypes, int32(t))
+	}
+	data, total, err := s.LikeModel.FindManyAndCount(ctx, filter, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &LikeTimeline{
+		Items: make([]*LikeTimelineItem, 0, len(data)),
+		Total: total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		res.Items = append(res.Items, &LikeTimelineItem{
+			TargetId:     alike.TargetId,
+			TargetType:   alike.TargetType,
+			AssociatedId: alike.AssociatedId,
+			Reaction:     alike.GetReaction(),
+			LikeTime:     alike.CreateAt,
+		})
+	}
+	return res, nil
+}
+
+// GetLikeHistory 查询目标的点赞历史，userId不为空时只查询该用户对目标的点赞历史
+func (s *LikeServiceImpl) GetLikeHistory(ctx context.Context, userId string, targetId string, likeType user.LikeType, paginationOptions *basic.PaginationOptions) (*LikeHistory, error) {
+	p := util.ParsePagination(paginationOptions)
+	targetType := int32(likeType)
+	filter := &like.FilterOptions{
+		OnlyTargetId:   &targetId,
+		OnlyTargetType: &targetType,
+		IncludeDeleted: true,
+	}
+	if userId != "" {
+		filter.OnlyUserId = &userId
+	}
+	data, total, err := s.LikeModel.FindManyAndCount(ctx, filter, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &LikeHistory{
+		Events: make([]*LikeEvent, 0, 2*len(data)),
+		Total:  total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		if !alike.DeletedAt.IsZero() {
+			res.Events = append(res.Events, &LikeEvent{
+				UserId:     alike.UserId,
+				TargetId:   alike.TargetId,
+				TargetType: alike.TargetType,
+				Liked:      false,
+				Time:       alike.DeletedAt,
+			})
+		}
+		res.Events = append(res.Events, &LikeEvent{
+			UserId:     alike.UserId,
+			TargetId:   alike.TargetId,
+			TargetType: alike.TargetType,
+			Liked:      true,
+			Time:       alike.CreateAt,
+		})
+	}
+	return res, nil
+}
+
+// GetAssociatedLikes 统计关联到associatedId的点赞总数以及点赞最多的topN个子目标，likeType为Unknown时不限制子目标类型
+func (s *LikeServiceImpl) GetAssociatedLikes(ctx context.Context, associatedId string, likeType user.LikeType, topN int64) (*AssociatedLikes, error) {
+	if topN <= 0 || topN > MaxBatchTargets {
+		topN = MaxBatchTargets
+	}
+	var targetType *int64
+	if likeType != user.LikeType_Unknown {
+		t := int64(likeType)
+		targetType = &t
+	}
+	total, children, err := s.LikeModel.CountByAssociatedId(ctx, associatedId, targetType, topN)
+	if err != nil {
+		return nil, consts.ErrDataBase
+	}
+	return &AssociatedLikes{
+		Total:    total,
+		Children: children,
+	}, nil
+}
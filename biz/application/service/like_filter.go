@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/bloom"
+	"github.com/zeromicro/go-zero/core/threading"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+// 每个用户一个布隆过滤器，记录其点赞过的目标，用于在不访问Mongo的情况下判定"未点赞"。
+// 过滤器只在完整重建后才被信任，取消点赞不会从中移除，因此判定存在时仍需查询Mongo。
+const (
+	prefixLikeFilterKey         = "likeFilter:"
+	prefixLikeFilterReadyKey    = "likeFilterReady:"
+	prefixLikeFilterBuildingKey = "likeFilterBuilding:"
+	// 点赞数超过过滤器容量的用户在标记过期前不再尝试重建
+	prefixLikeFilterTooLargeKey = "likeFilterTooLarge:"
+	likeFilterBuildTimeout      = 60
+	// go-zero的布隆过滤器每个元素约需20位才能保证误判率低于万分之一
+	likeFilterBitsPerElement = 20
+)
+
+func (s *LikeServiceImpl) likeFilter(userId string) *bloom.Filter {
+	return bloom.New(s.Redis, prefixLikeFilterKey+userId, uint(s.Config.LikeFilter.Bits))
+}
+
+func likeFilterElement(targetId string, targetType int64) []byte {
+	return []byte(strconv.FormatInt(targetType, 10) + ":" + targetId)
+}
+
+// mayHaveLiked 返回false表示用户一定没有点赞过该目标，过滤器不可用时返回true
+func (s *LikeServiceImpl) mayHaveLiked(ctx context.Context, userId string, targetId string, targetType int64) bool {
+	if s.Config.LikeFilter.Bits <= 0 {
+		return true
+	}
+	ready, err := s.Redis.ExistsCtx(ctx, prefixLikeFilterReadyKey+userId)
+	if err != nil {
+		log.CtxError(ctx, "check like filter ready fail, userId=%s, err=%v", userId, err)
+		return true
+	}
+	if !ready {
+		tooLarge, err := s.Redis.ExistsCtx(ctx, prefixLikeFilterTooLargeKey+userId)
+		if err != nil {
+			log.CtxError(ctx, "check like filter too large fail, userId=%s, err=%v", userId, err)
+			return true
+		}
+		if !tooLarge {
+			s.buildLikeFilter(userId)
+		}
+		return true
+	}
+	exists, err := s.likeFilter(userId).ExistsCtx(ctx, likeFilterElement(targetId, targetType))
+	if err != nil {
+		log.CtxError(ctx, "check like filter fail, userId=%s, err=%v", userId, err)
+		return true
+	}
+	return exists
+}
+
+// addLikeFilter 点赞后无论过滤器是否可用都写入，保证重建期间的新点赞不会丢失
+// 写入会在过滤器不存在时创建key，每次写入后都刷新过期时间，避免留下不过期的key
+func (s *LikeServiceImpl) addLikeFilter(ctx context.Context, userId string, targetId string, targetType int64) {
+	if s.Config.LikeFilter.Bits <= 0 {
+		return
+	}
+	if err := s.likeFilter(userId).AddCtx(ctx, likeFilterElement(targetId, targetType)); err != nil {
+		// 写入失败时作废过滤器，避免之后误判为未点赞
+		log.CtxError(ctx, "add like filter fail, userId=%s, err=%v", userId, err)
+		if _, err = s.Redis.DelCtx(ctx, prefixLikeFilterReadyKey+userId); err != nil {
+			log.CtxError(ctx, "invalidate like filter fail, userId=%s, err=%v", userId, err)
+		}
+		return
+	}
+	// 过滤器比可用标记晚过期，保证标记存在时过滤器一定完整
+	if err := s.Redis.ExpireCtx(ctx, prefixLikeFilterKey+userId, int(s.Config.LikeFilter.Expire+likeFilterBuildTimeout)); err != nil {
+		log.CtxError(ctx, "expire like filter fail, userId=%s, err=%v", userId, err)
+	}
+}
+
+// buildLikeFilter 在后台将用户所有的点赞写入过滤器，完成后标记为可用，点赞数超过过滤器容量时标记为过大
+func (s *LikeServiceImpl) buildLikeFilter(userId string) {
+	threading.GoSafe(func() {
+		ctx := context.Background()
+		ok, err := s.Redis.SetnxExCtx(ctx, prefixLikeFilterBuildingKey+userId, "1", likeFilterBuildTimeout)
+		if err != nil || !ok {
+			return
+		}
+
+		capacity := s.Config.LikeFilter.Bits / likeFilterBitsPerElement
+		targets, err := s.LikeModel.ListUserTargets(ctx, userId, capacity+1)
+		if err != nil {
+			log.CtxError(ctx, "list user liked targets fail, userId=%s, err=%v", userId, err)
+			return
+		}
+		if int64(len(targets)) > capacity {
+			if err = s.Redis.SetexCtx(ctx, prefixLikeFilterTooLargeKey+userId, "1", int(s.Config.LikeFilter.Expire)); err != nil {
+				log.CtxError(ctx, "mark like filter too large fail, userId=%s, err=%v", userId, err)
+			}
+			return
+		}
+		filter := s.likeFilter(userId)
+		for _, t := range targets {
+			if err = filter.AddCtx(ctx, likeFilterElement(t.TargetId, t.TargetType)); err != nil {
+				log.CtxError(ctx, "build like filter fail, userId=%s, err=%v", userId, err)
+				return
+			}
+		}
+		// 过滤器比可用标记晚过期，保证标记存在时过滤器一定完整
+		if err = s.Redis.ExpireCtx(ctx, prefixLikeFilterKey+userId, int(s.Config.LikeFilter.Expire+likeFilterBuildTimeout)); err != nil {
+			log.CtxError(ctx, "expire like filter fail, userId=%s, err=%v", userId, err)
+			return
+		}
+		if err = s.Redis.SetexCtx(ctx, prefixLikeFilterReadyKey+userId, "1", int(s.Config.LikeFilter.Expire)); err != nil {
+			log.CtxError(ctx, "mark like filter ready fail, userId=%s, err=%v", userId, err)
+		}
+	})
+}
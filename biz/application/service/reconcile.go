@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/counter"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+type ReconcileService interface {
+	ReconcileCounters(ctx context.Context) (*ReconcileReport, error)
+}
+
+// ReconcileReport 一次计数器校对的结果
+type ReconcileReport struct {
+	Scanned     int64
+	Skipped     int64
+	Corrections []*CounterCorrection
+	StartAt     time.Time
+	Cost        time.Duration
+}
+
+// CounterCorrection 一个被修正的计数器，Before为修正前的计数，After为like集合中的实际点赞数
+type CounterCorrection struct {
+	TargetId   string
+	TargetType int64
+	Before     int64
+	After      int64
+}
+
+type ReconcileServiceImpl struct {
+	Config       *config.Config
+	LikeModel    like.IMongoMapper
+	CounterModel counter.IMongoMapper
+}
+
+var ReconcileSet = wire.NewSet(
+	wire.Struct(new(ReconcileServiceImpl), "*"),
+	wire.Bind(new(ReconcileService), new(*ReconcileServiceImpl)),
+)
+
+// defaultReconcileBatchSize 配置的BatchSize不为正数时使用
+const defaultReconcileBatchSize = 500
+
+// ReconcileCounters 分批扫描所有计数器，按like集合重新统计点赞数并修正不一致的计数器
+func (s *ReconcileServiceImpl) ReconcileCounters(ctx context.Context) (*ReconcileReport, error) {
+	report := &ReconcileReport{StartAt: time.Now()}
+	batchSize := s.Config.Reconcile.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+	var lastId primitive.ObjectID
+	for {
+		counters, err := s.CounterModel.ScanMany(ctx, lastId, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(counters) == 0 {
+			break
+		}
+		for _, c := range counters {
+			if err = s.reconcile(ctx, c, report); err != nil {
+				return nil, err
+			}
+		}
+		report.Scanned += int64(len(counters))
+		if int64(len(counters)) < batchSize {
+			break
+		}
+		lastId = counters[len(counters)-1].ID
+	}
+	report.Cost = time.Since(report.StartAt)
+
+	for _, c := range report.Corrections {
+		log.CtxInfo(ctx, "like counter corrected, targetId=%s, targetType=%d, before=%d, after=%d", c.TargetId, c.TargetType, c.Before, c.After)
+	}
+	log.CtxInfo(ctx, "reconcile like counters finished, scanned=%d, corrected=%d, skipped=%d, cost=%v",
+		report.Scanned, len(report.Corrections), report.Skipped, report.Cost)
+	return report, nil
+}
+
+func (s *ReconcileServiceImpl) reconcile(ctx context.Context, c *counter.Counter, report *ReconcileReport) error {
+	targetType := int32(c.TargetType)
+	count, err := s.LikeModel.Count(ctx, &like.FilterOptions{
+		OnlyTargetId:   &c.TargetId,
+		OnlyTargetType: &targetType,
+	})
+	if err != nil {
+		return err
+	}
+	if count == c.Count {
+		return nil
+	}
+	// 扫描期间计数器可能被点赞修改过，此时跳过，留到下一次校对
+	ok, err := s.CounterModel.CompareAndSet(ctx, c.TargetId, c.TargetType, c.Count, count)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		report.Skipped++
+		return nil
+	}
+	report.Corrections = append(report.Corrections, &CounterCorrection{
+		TargetId:   c.TargetId,
+		TargetType: c.TargetType,
+		Before:     c.Count,
+		After:      count,
+	})
+	return nil
+}
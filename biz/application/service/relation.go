@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"github.com/zeromicro/go-zero/core/mr"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+)
+
+// RelationService 关注关系，复用like集合中LikeType_User类型的点赞
+type RelationService interface {
+	Follow(ctx context.Context, userId string, followeeId string) (*LikeResult, error)
+	Unfollow(ctx context.Context, userId string, followeeId string) (*LikeResult, error)
+	GetFollowers(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RelationList, error)
+	GetFollowing(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RelationList, error)
+	IsMutualFollow(ctx context.Context, userId string, otherId string) (bool, error)
+	GetRelationCount(ctx context.Context, userId string) (*RelationCount, error)
+}
+
+type RelationList struct {
+	UserIds []string
+	Total   int64
+	Token   string
+}
+
+type RelationCount struct {
+	Followers int64
+	Following int64
+}
+
+type RelationServiceImpl struct {
+	LikeService LikeService
+	LikeModel   like.IMongoMapper
+}
+
+var RelationSet = wire.NewSet(
+	wire.Struct(new(RelationServiceImpl), "*"),
+	wire.Bind(new(RelationService), new(*RelationServiceImpl)),
+)
+
+func (s *RelationServiceImpl) Follow(ctx context.Context, userId string, followeeId string) (*LikeResult, error) {
+	if userId == followeeId {
+		return nil, consts.ErrFollowSelf
+	}
+	return s.LikeService.Like(ctx, &user.DoLikeReq{
+		UserId:      userId,
+		TargetId:    followeeId,
+		Type:        user.LikeType_User,
+		LikedUserId: followeeId,
+	})
+}
+
+func (s *RelationServiceImpl) Unfollow(ctx context.Context, userId string, followeeId string) (*LikeResult, error) {
+	return s.LikeService.Unlike(ctx, &user.DoLikeReq{
+		UserId:      userId,
+		TargetId:    followeeId,
+		Type:        user.LikeType_User,
+		LikedUserId: followeeId,
+	})
+}
+
+func (s *RelationServiceImpl) GetFollowers(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RelationList, error) {
+	p := util.ParsePagination(paginationOptions)
+	targetType := int32(user.LikeType_User)
+	data, total, err := s.LikeModel.FindManyAndCount(ctx, &like.FilterOptions{
+		OnlyTargetId:   &userId,
+		OnlyTargetType: &targetType,
+	}, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &RelationList{
+		UserIds: make([]string, 0, len(data)),
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		res.UserIds = append(res.UserIds, alike.UserId)
+	}
+	return res, nil
+}
+
+func (s *RelationServiceImpl) GetFollowing(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RelationList, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.LikeModel.GetUserLikes(ctx, userId, int64(user.LikeType_User), p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &RelationList{
+		UserIds: make([]string, 0, len(data)),
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	for _, alike := range data {
+		res.UserIds = append(res.UserIds, alike.TargetId)
+	}
+	return res, nil
+}
+
+func (s *RelationServiceImpl) IsMutualFollow(ctx context.Context, userId string, otherId string) (bool, error) {
+	var following, followed bool
+	if err := mr.Finish(func() error {
+		var err error
+		following, err = s.isFollowing(ctx, userId, otherId)
+		return err
+	}, func() error {
+		var err error
+		followed, err = s.isFollowing(ctx, otherId, userId)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return following && followed, nil
+}
+
+func (s *RelationServiceImpl) GetRelationCount(ctx context.Context, userId string) (*RelationCount, error) {
+	res := new(RelationCount)
+	targetType := int32(user.LikeType_User)
+	if err := mr.Finish(func() error {
+		var err error
+		res.Followers, err = s.LikeModel.Count(ctx, &like.FilterOptions{
+			OnlyTargetId:   &userId,
+			OnlyTargetType: &targetType,
+		})
+		return err
+	}, func() error {
+		var err error
+		res.Following, err = s.LikeModel.CountUserLikes(ctx, userId, int64(user.LikeType_User))
+		return err
+	}); err != nil {
+		return nil, consts.ErrDataBase
+	}
+	return res, nil
+}
+
+func (s *RelationServiceImpl) isFollowing(ctx context.Context, userId string, followeeId string) (bool, error) {
+	err := s.LikeModel.GetUserLike(ctx, userId, followeeId, int64(user.LikeType_User))
+	switch err {
+	case nil:
+		return true, nil
+	case monc.ErrNotFound:
+		return false, nil
+	default:
+		return false, consts.ErrDataBase
+	}
+}
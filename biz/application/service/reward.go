@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/basic"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/reward"
+	usermapper "github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/user"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+type RewardService interface {
+	RewardLike(ctx context.Context, req *user.DoLikeReq, likeId string) (getFish bool, getFishTimes int64)
+	GrantFish(ctx context.Context, userId string, likeId string, amount int64, reason string) error
+	GetRewardHistory(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RewardHistory, error)
+	GetTodayReward(ctx context.Context, userId string) (int64, error)
+}
+
+// RewardHistory 用户的小鱼干发放记录
+type RewardHistory struct {
+	Rewards []*reward.Reward
+	Total   int64
+	Token   string
+}
+
+type RewardServiceImpl struct {
+	Config          *config.Config
+	RewardModel     reward.IMongoMapper
+	UserMongoMapper usermapper.IMongoMapper
+	Redis           *redis.Redis
+	Clock           util.Clock
+	Policy          RewardPolicy
+}
+
+var RewardSet = wire.NewSet(
+	wire.Struct(new(RewardServiceImpl), "*"),
+	wire.Bind(new(RewardService), new(*RewardServiceImpl)),
+	wire.Struct(new(ConfigRewardPolicy), "*"),
+	wire.Bind(new(RewardPolicy), new(*ConfigRewardPolicy)),
+)
+
+// likeRewardScript 原子地累加当天的点赞次数并判断是否还能获得小鱼干，同一目标当天只计算一次
+// KEYS[1] 计数key, KEYS[2] 当天已计算过的目标集合
+// ARGV[1] 每日上限, ARGV[2] 过期的unix时间戳, ARGV[3] 目标标识
+// 返回 {累加后的次数, 是否发放(1/0)}
+var likeRewardScript = redis.NewScript(`
+if redis.call("SADD", KEYS[2], ARGV[3]) == 0 then
+	return {tonumber(redis.call("GET", KEYS[1]) or "0"), 0}
+end
+redis.call("EXPIREAT", KEYS[2], ARGV[2])
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("EXPIREAT", KEYS[1], ARGV[2])
+end
+if count <= tonumber(ARGV[1]) then
+	return {count, 1}
+end
+return {count, 0}
+`)
+
+// rollbackLikeRewardScript 撤销likeRewardScript对目标的一次计数
+// KEYS同likeRewardScript, ARGV[1] 目标标识
+var rollbackLikeRewardScript = redis.NewScript(`
+if redis.call("SREM", KEYS[2], ARGV[1]) == 1 then
+	return redis.call("DECR", KEYS[1])
+end
+return tonumber(redis.call("GET", KEYS[1]) or "0")
+`)
+
+// RewardLike 为一次新的点赞计算并发放小鱼干，奖励失败不影响点赞本身
+func (s *RewardServiceImpl) RewardLike(ctx context.Context, req *user.DoLikeReq, likeId string) (getFish bool, getFishTimes int64) {
+	rule := s.Policy.Evaluate(ctx, req.UserId, req.Type)
+	if !rule.Eligible {
+		return false, 0
+	}
+
+	start, end := util.DayRange(s.Clock.Now(), s.location(ctx, req.UserId))
+	day := start.Format("20060102")
+	// 使用hash tag保证集群模式下两个key在同一个slot
+	keys := []string{
+		"likeReward:{" + req.UserId + "}:" + rule.Bucket + ":" + day,
+		"likeRewardTargets:{" + req.UserId + "}:" + day,
+	}
+	target := strconv.FormatInt(int64(req.Type), 10) + ":" + req.TargetId
+	val, err := s.Redis.ScriptRunCtx(ctx, likeRewardScript, keys, rule.DailyCap, end.Unix(), target)
+	if err != nil {
+		log.CtxError(ctx, "run like reward script fail, userId=%s, err=%v", req.UserId, err)
+		return false, 0
+	}
+	r, ok := val.([]any)
+	if !ok || len(r) != 2 {
+		log.CtxError(ctx, "unexpected like reward script result, userId=%s, val=%v", req.UserId, val)
+		return false, 0
+	}
+	times, _ := r[0].(int64)
+	granted, _ := r[1].(int64)
+	if granted != 1 {
+		return false, times
+	}
+
+	if err = s.GrantFish(ctx, req.UserId, likeId, rule.Amount, reward.ReasonLike); err != nil {
+		log.CtxError(ctx, "grant fish fail, userId=%s, likeId=%s, err=%v", req.UserId, likeId, err)
+		// 没有发放记录时撤销本次计数，保证返回结果与发放记录一致，之后再次点赞该目标仍可获得奖励
+		val, err = s.Redis.ScriptRunCtx(ctx, rollbackLikeRewardScript, keys, target)
+		if err != nil {
+			log.CtxError(ctx, "rollback like reward fail, userId=%s, err=%v", req.UserId, err)
+			return false, times
+		}
+		times, _ = val.(int64)
+		return false, times
+	}
+	return true, times
+}
+
+func (s *RewardServiceImpl) GrantFish(ctx context.Context, userId string, likeId string, amount int64, reason string) error {
+	err := s.RewardModel.Insert(ctx, &reward.Reward{
+		ID:       primitive.NewObjectID(),
+		UserId:   userId,
+		LikeId:   likeId,
+		Amount:   amount,
+		Reason:   reason,
+		CreateAt: s.Clock.Now(),
+	})
+	if err != nil {
+		return consts.ErrDataBase
+	}
+	return nil
+}
+
+func (s *RewardServiceImpl) GetRewardHistory(ctx context.Context, userId string, paginationOptions *basic.PaginationOptions) (*RewardHistory, error) {
+	p := util.ParsePagination(paginationOptions)
+	data, total, err := s.RewardModel.FindManyAndCount(ctx, userId, p, mongop.IdCursorType)
+	if err != nil {
+		return nil, err
+	}
+	res := &RewardHistory{
+		Rewards: data,
+		Total:   total,
+	}
+	if p.LastToken != nil {
+		res.Token = *p.LastToken
+	}
+	return res, nil
+}
+
+func (s *RewardServiceImpl) GetTodayReward(ctx context.Context, userId string) (int64, error) {
+	start, _ := util.DayRange(s.Clock.Now(), s.location(ctx, userId))
+	total, err := s.RewardModel.SumAmount(ctx, userId, start)
+	if err != nil {
+		return 0, consts.ErrDataBase
+	}
+	return total, nil
+}
+
+// location 返回用户计算奖励日期所用的时区，用户设置的时区优先于服务配置，用户时区无效时使用服务配置
+func (s *RewardServiceImpl) location(ctx context.Context, userId string) *time.Location {
+	if u, err := s.UserMongoMapper.FindOne(ctx, userId); err == nil && u.Timezone != "" {
+		if loc, err := util.LoadLocation(u.Timezone); err == nil {
+			return loc
+		}
+	}
+	// RewardTimezone已在启动时校验
+	loc, err := util.LoadLocation(s.Config.RewardTimezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
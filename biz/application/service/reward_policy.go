@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+)
+
+// RewardPolicy 决定一次点赞适用的奖励规则
+type RewardPolicy interface {
+	Evaluate(ctx context.Context, userId string, likeType user.LikeType) *RewardRule
+}
+
+// RewardRule 一次点赞适用的奖励规则，Bucket相同的规则共享每日上限
+type RewardRule struct {
+	Eligible bool
+	Amount   int64
+	DailyCap int64
+	Bucket   string
+}
+
+const defaultRewardBucket = "default"
+
+// ConfigRewardPolicy 根据config.RewardRules计算奖励规则
+type ConfigRewardPolicy struct {
+	Config *config.Config
+}
+
+func (p *ConfigRewardPolicy) Evaluate(_ context.Context, _ string, likeType user.LikeType) *RewardRule {
+	if r, ok := p.Config.RewardRules[likeType.String()]; ok {
+		rule := &RewardRule{
+			Eligible: r.Eligible,
+			Amount:   r.Amount,
+			DailyCap: r.DailyCap,
+			Bucket:   likeType.String(),
+		}
+		if rule.DailyCap == 0 {
+			rule.DailyCap = p.Config.LikeTimes
+		}
+		return rule
+	}
+	// 未配置的类型沿用原有规则：关注用户没有奖励，其余类型共享LikeTimes上限
+	return &RewardRule{
+		Eligible: likeType != user.LikeType_User,
+		Amount:   1,
+		DailyCap: p.Config.LikeTimes,
+		Bucket:   defaultRewardBucket,
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/reward"
+	usermapper "github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/user"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+type fakeRewardMapper struct {
+	reward.IMongoMapper
+	rewards []*reward.Reward
+	err     error
+}
+
+func (m *fakeRewardMapper) Insert(_ context.Context, data *reward.Reward) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.rewards = append(m.rewards, data)
+	return nil
+}
+
+type fakeUserMapper struct {
+	usermapper.IMongoMapper
+	users map[string]*usermapper.User
+}
+
+func (m *fakeUserMapper) FindOne(_ context.Context, id string) (*usermapper.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, consts.ErrNotFound
+}
+
+func newTestRewardService(t *testing.T, clock *fixedClock, users map[string]*usermapper.User) (*RewardServiceImpl, *fakeRewardMapper) {
+	t.Helper()
+	c := &config.Config{LikeTimes: 2, RewardTimezone: "Asia/Shanghai"}
+	rewards := &fakeRewardMapper{}
+	return &RewardServiceImpl{
+		Config:          c,
+		RewardModel:     rewards,
+		UserMongoMapper: &fakeUserMapper{users: users},
+		Redis:           redis.New(miniredis.RunT(t).Addr()),
+		Clock:           clock,
+		Policy:          &ConfigRewardPolicy{Config: c},
+	}, rewards
+}
+
+func TestRewardLikeAcrossMidnight(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	clock := &fixedClock{}
+	s, rewards := newTestRewardService(t, clock, nil)
+	ctx := context.Background()
+
+	steps := []struct {
+		now       time.Time
+		targetId  string
+		wantFish  bool
+		wantTimes int64
+	}{
+		{time.Date(2026, 10, 18, 23, 59, 0, 0, shanghai), "a", true, 1},
+		// 同一天内重复点赞同一目标不计次数
+		{time.Date(2026, 10, 18, 23, 59, 10, 0, shanghai), "a", false, 1},
+		{time.Date(2026, 10, 18, 23, 59, 20, 0, shanghai), "b", true, 2},
+		{time.Date(2026, 10, 18, 23, 59, 59, 0, shanghai), "c", false, 3},
+		// 零点后重新计算，前一天点赞过的目标也可以再次获得奖励
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai), "a", true, 1},
+		{time.Date(2026, 10, 19, 0, 0, 30, 0, shanghai), "c", true, 2},
+	}
+	for i, step := range steps {
+		clock.now = step.now
+		getFish, times := s.RewardLike(ctx, &user.DoLikeReq{UserId: "u1", TargetId: step.targetId, Type: user.LikeType_Post}, "like")
+		if getFish != step.wantFish || times != step.wantTimes {
+			t.Fatalf("step %d at %v: got (%v, %d), want (%v, %d)", i, step.now, getFish, times, step.wantFish, step.wantTimes)
+		}
+	}
+	if len(rewards.rewards) != 4 {
+		t.Fatalf("granted %d rewards, want 4", len(rewards.rewards))
+	}
+}
+
+func TestRewardLikeUserTimezone(t *testing.T) {
+	uid := "5f4e3d2c1b0a998877665544"
+	clock := &fixedClock{}
+	s, _ := newTestRewardService(t, clock, map[string]*usermapper.User{
+		uid: {Timezone: "America/New_York"},
+	})
+	ctx := context.Background()
+
+	clock.now = time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	if getFish, times := s.RewardLike(ctx, &user.DoLikeReq{UserId: uid, TargetId: "a", Type: user.LikeType_Post}, "like"); !getFish || times != 1 {
+		t.Fatalf("got (%v, %d), want (true, 1)", getFish, times)
+	}
+	// 上海已经是10月19日，但用户所在时区仍是10月18日，两次点赞计入同一天
+	clock.now = time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC)
+	if getFish, times := s.RewardLike(ctx, &user.DoLikeReq{UserId: uid, TargetId: "b", Type: user.LikeType_Post}, "like"); !getFish || times != 2 {
+		t.Fatalf("got (%v, %d), want (true, 2)", getFish, times)
+	}
+	// 用户所在时区的零点之后重新计算
+	clock.now = time.Date(2026, 10, 19, 4, 0, 0, 0, time.UTC)
+	if getFish, times := s.RewardLike(ctx, &user.DoLikeReq{UserId: uid, TargetId: "c", Type: user.LikeType_Post}, "like"); !getFish || times != 1 {
+		t.Fatalf("got (%v, %d), want (true, 1)", getFish, times)
+	}
+}
+
+func TestRewardLikeGrantFail(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	clock := &fixedClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, shanghai)}
+	s, rewards := newTestRewardService(t, clock, nil)
+	ctx := context.Background()
+	req := &user.DoLikeReq{UserId: "u1", TargetId: "a", Type: user.LikeType_Post}
+
+	rewards.err = consts.ErrDataBase
+	if getFish, times := s.RewardLike(ctx, req, "like"); getFish || times != 0 {
+		t.Fatalf("got (%v, %d), want (false, 0)", getFish, times)
+	}
+	// 发放失败的计数已撤销，再次点赞同一目标可以获得奖励
+	rewards.err = nil
+	if getFish, times := s.RewardLike(ctx, req, "like"); !getFish || times != 1 {
+		t.Fatalf("got (%v, %d), want (true, 1)", getFish, times)
+	}
+	if len(rewards.rewards) != 1 {
+		t.Fatalf("granted %d rewards, want 1", len(rewards.rewards))
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/threading"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+type TrendingWindow string
+
+const (
+	TrendingHour TrendingWindow = "hour"
+	TrendingDay  TrendingWindow = "day"
+	TrendingWeek TrendingWindow = "week"
+)
+
+var trendingWindows = map[TrendingWindow]time.Duration{
+	TrendingHour: time.Hour,
+	TrendingDay:  24 * time.Hour,
+	TrendingWeek: 7 * 24 * time.Hour,
+}
+
+type TrendingService interface {
+	GetTrending(ctx context.Context, likeType user.LikeType, window TrendingWindow, decay bool, offset int64, limit int64) ([]*TrendingTarget, error)
+	RefreshTrending(ctx context.Context) error
+	RemoveTarget(ctx context.Context, targetId string, likeType user.LikeType)
+}
+
+type TrendingTarget struct {
+	TargetId string
+	Score    float64
+}
+
+type TrendingServiceImpl struct {
+	Config    *config.Config
+	LikeModel like.IMongoMapper
+	Redis     *redis.Redis
+	Clock     util.Clock
+}
+
+var TrendingSet = wire.NewSet(
+	wire.Struct(new(TrendingServiceImpl), "*"),
+	wire.Bind(new(TrendingService), new(*TrendingServiceImpl)),
+)
+
+// replaceZsetScript 原子地用新的成员替换整个有序集合，并写入标记key表示该集合已经计算过，
+// 用于区分"计算结果为空"和"尚未计算"
+// KEYS[1] 有序集合key, KEYS[2] 标记key
+// ARGV[1] 过期秒数，为0时不过期, ARGV[2] 标记的值, ARGV[3...] 依次为score和member
+var replaceZsetScript = redis.NewScript(`
+redis.call("DEL", KEYS[1])
+for i = 3, #ARGV, 2 do
+	redis.call("ZADD", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call("SET", KEYS[2], ARGV[2])
+if tonumber(ARGV[1]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+	redis.call("EXPIRE", KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// refreshLockExpire 缓存缺失时触发的后台重算的锁过期秒数，过期前不会重复触发
+const refreshLockExpire = 60
+
+// goRefresh 在后台执行重算，同一lockKey在锁过期前只会执行一次，请求本身不等待重算结果
+func goRefresh(rds *redis.Redis, lockKey string, refresh func(ctx context.Context) error) {
+	threading.GoSafe(func() {
+		ctx := context.Background()
+		ok, err := rds.SetnxExCtx(ctx, lockKey, "1", refreshLockExpire)
+		if err != nil || !ok {
+			return
+		}
+		if err = refresh(ctx); err != nil {
+			log.CtxError(ctx, "refresh cache fail, lock=%s, err=%v", lockKey, err)
+		}
+	})
+}
+
+// GetTrending 从缓存中读取热门目标，缓存只由后台任务计算，尚未计算时触发一次后台计算并返回空结果
+func (s *TrendingServiceImpl) GetTrending(ctx context.Context, likeType user.LikeType, window TrendingWindow, decay bool, offset int64, limit int64) ([]*TrendingTarget, error) {
+	if _, ok := trendingWindows[window]; !ok {
+		return nil, consts.ErrInvalidWindow
+	}
+	if limit <= 0 || limit > MaxBatchTargets {
+		limit = MaxBatchTargets
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	key := trendingKey(likeType, window, decay)
+	pairs, err := s.Redis.ZrevrangeWithScoresByFloatCtx(ctx, key, offset, offset+limit-1)
+	if err != nil {
+		log.CtxError(ctx, "read trending cache fail, key=%s, err=%v", key, err)
+		return nil, consts.ErrDataBase
+	}
+	res := make([]*TrendingTarget, 0, len(pairs))
+	for _, p := range pairs {
+		res = append(res, &TrendingTarget{TargetId: p.Key, Score: p.Score})
+	}
+	if len(pairs) == 0 && offset == 0 {
+		ready, err := s.Redis.ExistsCtx(ctx, key+":ready")
+		if err != nil {
+			log.CtxError(ctx, "check trending cache fail, key=%s, err=%v", key, err)
+		} else if !ready {
+			goRefresh(s.Redis, "trendingRefresh:"+key, func(ctx context.Context) error {
+				return s.refresh(ctx, likeType, window, decay)
+			})
+		}
+	}
+	return res, nil
+}
+
+// RefreshTrending 重新计算所有类型和时间窗口的热门目标并写入缓存
+func (s *TrendingServiceImpl) RefreshTrending(ctx context.Context) error {
+	for _, likeType := range trendingLikeTypes() {
+		for window := range trendingWindows {
+			for _, decay := range []bool{false, true} {
+				if err := s.refresh(ctx, likeType, window, decay); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *TrendingServiceImpl) refresh(ctx context.Context, likeType user.LikeType, window TrendingWindow, decay bool) error {
+	now := s.Clock.Now()
+	var gravity float64
+	if decay {
+		gravity = s.Config.Trending.Gravity
+	}
+	data, err := s.LikeModel.CountTrending(ctx, int64(likeType), now.Add(-trendingWindows[window]), now, gravity, s.Config.Trending.Size)
+	if err != nil {
+		return err
+	}
+
+	// 缓存保留两个刷新周期，刷新任务停止时旧数据会自然过期
+	args := make([]any, 0, 2*len(data)+2)
+	args = append(args, 2*s.Config.Trending.RefreshInterval, 1)
+	for _, d := range data {
+		args = append(args, strconv.FormatFloat(d.Score, 'f', -1, 64), d.TargetId)
+	}
+	key := trendingKey(likeType, window, decay)
+	if _, err = s.Redis.ScriptRunCtx(ctx, replaceZsetScript, []string{key, key + ":ready"}, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveTarget 从所有时间窗口的热门榜单中移除被删除的目标
+func (s *TrendingServiceImpl) RemoveTarget(ctx context.Context, targetId string, likeType user.LikeType) {
+	for window := range trendingWindows {
+		for _, decay := range []bool{false, true} {
+			key := trendingKey(likeType, window, decay)
+			if _, err := s.Redis.ZremCtx(ctx, key, targetId); err != nil {
+				log.CtxError(ctx, "remove trending target fail, key=%s, targetId=%s, err=%v", key, targetId, err)
+			}
+		}
+	}
+}
+
+// trendingKey 使用hash tag保证集群模式下榜单和标记key在同一个slot
+func trendingKey(likeType user.LikeType, window TrendingWindow, decay bool) string {
+	key := strconv.FormatInt(int64(likeType), 10) + ":" + string(window)
+	if decay {
+		key += ":decay"
+	}
+	return "trending:{" + key + "}"
+}
+
+func trendingLikeTypes() []user.LikeType {
+	types := make([]user.LikeType, 0, len(user.LikeType_name))
+	for t := range user.LikeType_name {
+		if user.LikeType(t) != user.LikeType_Unknown {
+			types = append(types, user.LikeType(t))
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
 	usermapper "github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/user"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
 )
 
 type UserService interface {
@@ -21,12 +22,14 @@ type UserService interface {
 	GetUserDetail(ctx context.Context, req *genuser.GetUserDetailReq) (res *genuser.GetUserDetailResp, err error)
 	UpdateUser(ctx context.Context, req *genuser.UpdateUserReq) (res *genuser.UpdateUserResp, err error)
 	SearchUser(ctx context.Context, req *genuser.SearchUserReq) (res *genuser.SearchUserResp, err error)
+	UpdateTimezone(ctx context.Context, userId string, timezone string) error
 }
 
 type UserServiceImpl struct {
 	Config          *config.Config
 	UserMongoMapper usermapper.IMongoMapper
 	UserEsMapper    usermapper.IEsMapper
+	ActivityService ActivityService
 }
 
 var UserSet = wire.NewSet(
@@ -101,10 +104,28 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, req *genuser.UpdateUse
 	if err != nil {
 		return nil, err
 	}
+	s.ActivityService.RecordProfileUpdate(ctx, req.User.Id)
 
 	return &genuser.UpdateUserResp{}, nil
 }
 
+// UpdateTimezone 设置用户计算奖励日期所用的IANA时区(如Asia/Shanghai)，为空时使用服务配置的时区
+func (s *UserServiceImpl) UpdateTimezone(ctx context.Context, userId string, timezone string) error {
+	oid, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return consts.ErrInvalidObjectId
+	}
+	if timezone != "" {
+		if _, err = util.LoadLocation(timezone); err != nil {
+			return consts.ErrInvalidTimezone
+		}
+	}
+	if err = s.UserMongoMapper.SetTimezone(ctx, oid, timezone); err != nil {
+		return consts.ErrDataBase
+	}
+	return nil
+}
+
 func (s *UserServiceImpl) SearchUser(ctx context.Context, req *genuser.SearchUserReq) (res *genuser.SearchUserResp, err error) {
 	popts := &pagination.PaginationOptions{
 		Limit:     req.Limit,
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/service"
@@ -15,6 +16,38 @@ type ElasticsearchConf struct {
 	Password  string
 }
 
+// RewardRuleConf 某种点赞类型的奖励规则，Eligible默认为true，需要显式配置为false才不发放，DailyCap为0时使用LikeTimes
+type RewardRuleConf struct {
+	Eligible bool  `json:",default=true"`
+	Amount   int64 `json:",default=1"`
+	DailyCap int64 `json:",optional"`
+}
+
+// TrendingConf 热门目标榜单，RefreshInterval单位为秒，Gravity为时间衰减指数
+type TrendingConf struct {
+	RefreshInterval int64   `json:",default=300"`
+	Size            int64   `json:",default=100"`
+	Gravity         float64 `json:",default=1.8"`
+}
+
+// LeaderboardConf 用户获赞排行榜，RebuildInterval单位为秒，每个榜单重建时保留前Size名
+type LeaderboardConf struct {
+	RebuildInterval int64 `json:",default=3600"`
+	Size            int64 `json:",default=1000"`
+}
+
+// ReconcileConf 点赞计数器校对，Interval单位为秒
+type ReconcileConf struct {
+	Interval  int64 `json:",default=86400"`
+	BatchSize int64 `json:",default=500"`
+}
+
+// LikeFilterConf 用户点赞目标的布隆过滤器，Bits为每个用户的位数，Expire单位为秒
+type LikeFilterConf struct {
+	Bits   int64 `json:",default=65536"`
+	Expire int64 `json:",default=604800"`
+}
+
 type Config struct {
 	service.ServiceConf
 	ListenOn string
@@ -26,6 +59,19 @@ type Config struct {
 	Elasticsearch ElasticsearchConf
 	Redis         *redis.RedisConf
 	LikeTimes     int64
+	// RewardTimezone 点赞奖励按该时区的零点重置，为空时使用服务器本地时区
+	RewardTimezone string `json:",optional"`
+	// RewardRules 按LikeType名称(如Post、Comment)配置的奖励规则，未配置的类型沿用LikeTimes
+	RewardRules map[string]RewardRuleConf `json:",optional"`
+	// LikeCooldown 同一用户对同一目标两次改变点赞状态的最小间隔(秒)
+	LikeCooldown int64 `json:",default=3"`
+	// LikeRateWindow 秒内每个用户最多点赞或取消点赞LikeRateLimit次
+	LikeRateWindow int64 `json:",default=60"`
+	LikeRateLimit  int64 `json:",default=60"`
+	Trending       TrendingConf
+	Leaderboard    LeaderboardConf
+	Reconcile      ReconcileConf
+	LikeFilter     LikeFilterConf
 }
 
 func NewConfig() (*Config, error) {
@@ -42,5 +88,10 @@ func NewConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.RewardTimezone != "" {
+		if _, err = time.LoadLocation(c.RewardTimezone); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
@@ -10,4 +10,13 @@ var (
 	ErrDataBase        = status.Error(10002, "database error")
 	ErrNoThisItem      = status.Error(10003, "no this item")
 	ErrOutOfTime       = status.Error(10004, "out of time")
+	ErrTooManyTargets  = status.Error(10005, "too many targets")
+	ErrLikeCooldown    = status.Error(10006, "like status changed too frequently")
+	ErrLikeRateLimit   = status.Error(10007, "too many like requests")
+	ErrFollowSelf      = status.Error(10008, "cannot follow yourself")
+	ErrInvalidWindow   = status.Error(10009, "invalid time window")
+	ErrInvalidReaction = status.Error(10010, "invalid reaction")
+	ErrFolderExists    = status.Error(10011, "folder already exists")
+	ErrInvalidFolder   = status.Error(10012, "invalid folder name")
+	ErrInvalidTimezone = status.Error(10013, "invalid timezone")
 )
@@ -1,13 +1,23 @@
 package consts
 
 const (
-	ID         = "_id"
-	UserId     = "userId"
-	TargetId   = "targetId"
-	TargetType = "targetType"
-	AvatarUrl  = "avatarUrl"
-	Nickname   = "nickname"
-	Motto      = "motto"
-	UpdateAt   = "updateAt"
-	CreateAt   = "createAt"
+	ID           = "_id"
+	UserId       = "userId"
+	TargetId     = "targetId"
+	TargetType   = "targetType"
+	AssociatedId = "associatedId"
+	LikedUserId  = "likedUserId"
+	Reaction     = "reaction"
+	FolderId     = "folderId"
+	Name         = "name"
+	Kind         = "kind"
+	AvatarUrl    = "avatarUrl"
+	Nickname     = "nickname"
+	Motto        = "motto"
+	Timezone     = "timezone"
+	UpdateAt     = "updateAt"
+	CreateAt     = "createAt"
+	DeletedAt    = "deletedAt"
+	Count        = "count"
+	Amount       = "amount"
 )
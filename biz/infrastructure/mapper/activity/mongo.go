@@ -0,0 +1,169 @@
+package activity
+
+import (
+	"context"
+	"time"
+
+	"github.com/xh-polaris/gopkg/pagination"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/zeromicro/go-zero/core/mr"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixActivityCacheKey = "cache:activity:"
+const CollectionName = "activity"
+
+const (
+	KindLiked          = "liked"
+	KindFollowed       = "followed"
+	KindProfileUpdated = "profileUpdated"
+)
+
+var _ IMongoMapper = (*MongoMapper)(nil)
+
+type (
+	IMongoMapper interface {
+		Insert(ctx context.Context, data *Activity) error
+		Delete(ctx context.Context, userId string, kind string, targetId string, targetType int64) error
+		DeleteByTarget(ctx context.Context, targetId string, targetType int64) (int64, error)
+		FindMany(ctx context.Context, userIds []string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Activity, error)
+		Count(ctx context.Context, userIds []string) (int64, error)
+		FindManyAndCount(ctx context.Context, userIds []string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Activity, int64, error)
+	}
+
+	MongoMapper struct {
+		conn *monc.Model
+	}
+
+	// Activity 用户的一条动态，如点赞、关注和修改资料
+	Activity struct {
+		ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+		UserId       string             `bson:"userId,omitempty" json:"userId,omitempty"`
+		Kind         string             `bson:"kind,omitempty" json:"kind,omitempty"`
+		TargetId     string             `bson:"targetId,omitempty" json:"targetId,omitempty"`
+		TargetType   int64              `bson:"targetType,omitempty" json:"targetType,omitempty"`
+		AssociatedId string             `bson:"associatedId,omitempty" json:"associatedId,omitempty"`
+		CreateAt     time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+	}
+)
+
+func NewMongoMapper(config *config.Config) IMongoMapper {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.CacheConf)
+	_, err := conn.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// 按用户读取动态，按_id倒序即按时间倒序
+		{
+			Keys: bson.D{
+				{Key: consts.UserId, Value: 1},
+				{Key: consts.ID, Value: -1},
+			},
+		},
+		// 目标被删除或取消点赞时清理动态
+		{
+			Keys: bson.D{
+				{Key: consts.TargetId, Value: 1},
+				{Key: consts.TargetType, Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: consts.AssociatedId, Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		log.Error("create activity index fail, err=%v", err)
+	}
+	return &MongoMapper{
+		conn: conn,
+	}
+}
+
+func (m *MongoMapper) Insert(ctx context.Context, data *Activity) error {
+	if data.ID.IsZero() {
+		data.ID = primitive.NewObjectID()
+		data.CreateAt = time.Now()
+	}
+
+	key := prefixActivityCacheKey + data.ID.Hex()
+	_, err := m.conn.InsertOne(ctx, key, data)
+	return err
+}
+
+// Delete 删除用户对某个目标的动态
+func (m *MongoMapper) Delete(ctx context.Context, userId string, kind string, targetId string, targetType int64) error {
+	_, err := m.conn.DeleteMany(ctx, bson.M{
+		consts.UserId:     userId,
+		consts.Kind:       kind,
+		consts.TargetId:   targetId,
+		consts.TargetType: targetType,
+	})
+	return err
+}
+
+// DeleteByTarget 删除所有用户对目标以及关联到该目标的动态
+func (m *MongoMapper) DeleteByTarget(ctx context.Context, targetId string, targetType int64) (int64, error) {
+	return m.conn.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{consts.TargetId: targetId, consts.TargetType: targetType},
+		bson.M{consts.AssociatedId: targetId},
+	}})
+}
+
+func (m *MongoMapper) FindMany(ctx context.Context, userIds []string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Activity, error) {
+	p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
+	filter := bson.M{consts.UserId: bson.M{"$in": userIds}}
+	sort, err := p.MakeSortOptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var data []*Activity
+	if err = m.conn.Find(ctx, &data, filter, &options.FindOptions{
+		Sort:  sort,
+		Limit: popts.Limit,
+		Skip:  popts.Offset,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 如果是反向查询，反转数据
+	if *popts.Backward {
+		for i := 0; i < len(data)/2; i++ {
+			data[i], data[len(data)-i-1] = data[len(data)-i-1], data[i]
+		}
+	}
+	if len(data) > 0 {
+		err = p.StoreCursor(ctx, data[0], data[len(data)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (m *MongoMapper) Count(ctx context.Context, userIds []string) (int64, error) {
+	return m.conn.CountDocuments(ctx, bson.M{consts.UserId: bson.M{"$in": userIds}})
+}
+
+func (m *MongoMapper) FindManyAndCount(ctx context.Context, userIds []string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Activity, int64, error) {
+	var data []*Activity
+	var total int64
+	if err := mr.Finish(func() error {
+		var err error
+		data, err = m.FindMany(ctx, userIds, popts, sorter)
+		return err
+	}, func() error {
+		var err error
+		total, err = m.Count(ctx, userIds)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
@@ -0,0 +1,167 @@
+package counter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixCounterCacheKey = "cache:like_counter:"
+const CollectionName = "like_counter"
+
+var _ IMongoMapper = (*MongoMapper)(nil)
+
+type (
+	IMongoMapper interface {
+		FindOne(ctx context.Context, targetId string, targetType int64) (*Counter, error)
+		FindMany(ctx context.Context, targets []like.Target) ([]*Counter, error)
+		Increase(ctx context.Context, targetId string, targetType int64, delta int64) error
+		Init(ctx context.Context, targetId string, targetType int64, count int64) error
+		ScanMany(ctx context.Context, afterId primitive.ObjectID, limit int64) ([]*Counter, error)
+		CompareAndSet(ctx context.Context, targetId string, targetType int64, old int64, count int64) (bool, error)
+		DeleteMany(ctx context.Context, targets []like.Target) error
+	}
+
+	MongoMapper struct {
+		conn *monc.Model
+	}
+
+	// Counter 某个目标的点赞数，由点赞和取消点赞时增量维护
+	Counter struct {
+		ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+		TargetId   string             `bson:"targetId,omitempty" json:"targetId,omitempty"`
+		TargetType int64              `bson:"targetType,omitempty" json:"targetType,omitempty"`
+		Count      int64              `bson:"count" json:"count"`
+		UpdateAt   time.Time          `bson:"updateAt,omitempty" json:"updateAt,omitempty"`
+		CreateAt   time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+	}
+)
+
+func NewMongoMapper(config *config.Config) IMongoMapper {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.CacheConf)
+	_, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.TargetId, Value: 1}, {Key: consts.TargetType, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Error("create like_counter index fail, err=%v", err)
+	}
+	return &MongoMapper{
+		conn: conn,
+	}
+}
+
+func cacheKey(targetId string, targetType int64) string {
+	return prefixCounterCacheKey + strconv.FormatInt(targetType, 10) + ":" + targetId
+}
+
+func (m *MongoMapper) FindOne(ctx context.Context, targetId string, targetType int64) (*Counter, error) {
+	var data Counter
+	err := m.conn.FindOne(ctx, cacheKey(targetId, targetType), &data, bson.M{consts.TargetId: targetId, consts.TargetType: targetType})
+	switch err {
+	case nil:
+		return &data, nil
+	case monc.ErrNotFound:
+		return nil, consts.ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *MongoMapper) FindMany(ctx context.Context, targets []like.Target) ([]*Counter, error) {
+	or := make(bson.A, 0, len(targets))
+	for _, t := range targets {
+		or = append(or, bson.M{consts.TargetId: t.TargetId, consts.TargetType: t.TargetType})
+	}
+	data := make([]*Counter, 0, len(targets))
+	if err := m.conn.Find(ctx, &data, bson.M{"$or": or}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Increase 仅更新已存在的计数器，缺失的计数器在下次读取时重新统计
+func (m *MongoMapper) Increase(ctx context.Context, targetId string, targetType int64, delta int64) error {
+	_, err := m.conn.UpdateOne(ctx, cacheKey(targetId, targetType),
+		bson.M{consts.TargetId: targetId, consts.TargetType: targetType},
+		bson.M{
+			"$inc": bson.M{consts.Count: delta},
+			"$set": bson.M{consts.UpdateAt: time.Now()},
+		})
+	return err
+}
+
+// Init 在计数器不存在时以count初始化，已存在时不做修改
+func (m *MongoMapper) Init(ctx context.Context, targetId string, targetType int64, count int64) error {
+	now := time.Now()
+	_, err := m.conn.UpdateOne(ctx, cacheKey(targetId, targetType),
+		bson.M{consts.TargetId: targetId, consts.TargetType: targetType},
+		bson.M{"$setOnInsert": bson.M{
+			consts.Count:    count,
+			consts.UpdateAt: now,
+			consts.CreateAt: now,
+		}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// ScanMany 按_id升序返回afterId之后的至多limit个计数器，afterId为零值时从头开始
+func (m *MongoMapper) ScanMany(ctx context.Context, afterId primitive.ObjectID, limit int64) ([]*Counter, error) {
+	filter := bson.M{}
+	if !afterId.IsZero() {
+		filter[consts.ID] = bson.M{"$gt": afterId}
+	}
+	data := make([]*Counter, 0, limit)
+	if err := m.conn.Find(ctx, &data, filter, &options.FindOptions{
+		Sort:  bson.M{consts.ID: 1},
+		Limit: &limit,
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CompareAndSet 仅在计数器当前值仍为old时将其设为count，返回是否修改成功
+func (m *MongoMapper) CompareAndSet(ctx context.Context, targetId string, targetType int64, old int64, count int64) (bool, error) {
+	res, err := m.conn.UpdateOne(ctx, cacheKey(targetId, targetType),
+		bson.M{consts.TargetId: targetId, consts.TargetType: targetType, consts.Count: old},
+		bson.M{"$set": bson.M{
+			consts.Count:    count,
+			consts.UpdateAt: time.Now(),
+		}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// DeleteMany 删除目标的计数器，下次读取时从like集合重新统计
+func (m *MongoMapper) DeleteMany(ctx context.Context, targets []like.Target) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	or := make(bson.A, 0, len(targets))
+	keys := make([]string, 0, len(targets))
+	for _, t := range targets {
+		or = append(or, bson.M{consts.TargetId: t.TargetId, consts.TargetType: t.TargetType})
+		keys = append(keys, cacheKey(t.TargetId, t.TargetType))
+	}
+	if _, err := m.conn.DeleteMany(ctx, bson.M{"$or": or}); err != nil {
+		return err
+	}
+	return m.conn.DelCache(ctx, keys...)
+}
@@ -0,0 +1,169 @@
+package favorite
+
+import (
+	"context"
+	"time"
+
+	"github.com/xh-polaris/gopkg/pagination"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/zeromicro/go-zero/core/mr"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixFolderCacheKey = "cache:favorite_folder:"
+const FolderCollectionName = "favorite_folder"
+
+var _ IFolderMongoMapper = (*FolderMongoMapper)(nil)
+
+type (
+	IFolderMongoMapper interface {
+		Insert(ctx context.Context, data *Folder) error
+		FindOne(ctx context.Context, id string) (*Folder, error)
+		Rename(ctx context.Context, id string, name string) error
+		Delete(ctx context.Context, id string) error
+		FindMany(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Folder, error)
+		Count(ctx context.Context, userId string) (int64, error)
+		FindManyAndCount(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Folder, int64, error)
+	}
+
+	FolderMongoMapper struct {
+		conn *monc.Model
+	}
+
+	// Folder 用户的收藏夹，同一用户的收藏夹不能重名
+	Folder struct {
+		ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+		UserId   string             `bson:"userId,omitempty" json:"userId,omitempty"`
+		Name     string             `bson:"name,omitempty" json:"name,omitempty"`
+		UpdateAt time.Time          `bson:"updateAt,omitempty" json:"updateAt,omitempty"`
+		CreateAt time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+	}
+)
+
+func NewFolderMongoMapper(config *config.Config) IFolderMongoMapper {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, FolderCollectionName, config.CacheConf)
+	_, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: consts.UserId, Value: 1}, {Key: consts.Name, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Error("create favorite_folder index fail, err=%v", err)
+	}
+	return &FolderMongoMapper{
+		conn: conn,
+	}
+}
+
+func (m *FolderMongoMapper) Insert(ctx context.Context, data *Folder) error {
+	if data.ID.IsZero() {
+		data.ID = primitive.NewObjectID()
+		data.CreateAt = time.Now()
+		data.UpdateAt = time.Now()
+	}
+
+	key := prefixFolderCacheKey + data.ID.Hex()
+	_, err := m.conn.InsertOne(ctx, key, data)
+	return err
+}
+
+func (m *FolderMongoMapper) FindOne(ctx context.Context, id string) (*Folder, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, consts.ErrInvalidObjectId
+	}
+
+	var data Folder
+	key := prefixFolderCacheKey + id
+	err = m.conn.FindOne(ctx, key, &data, bson.M{consts.ID: oid})
+	switch err {
+	case nil:
+		return &data, nil
+	case monc.ErrNotFound:
+		return nil, consts.ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *FolderMongoMapper) Rename(ctx context.Context, id string, name string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return consts.ErrInvalidObjectId
+	}
+	key := prefixFolderCacheKey + id
+	_, err = m.conn.UpdateOne(ctx, key, bson.M{consts.ID: oid}, bson.M{"$set": bson.M{
+		consts.Name:     name,
+		consts.UpdateAt: time.Now(),
+	}})
+	return err
+}
+
+func (m *FolderMongoMapper) Delete(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return consts.ErrInvalidObjectId
+	}
+	key := prefixFolderCacheKey + id
+	_, err = m.conn.DeleteOne(ctx, key, bson.M{consts.ID: oid})
+	return err
+}
+
+func (m *FolderMongoMapper) FindMany(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Folder, error) {
+	p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
+	filter := bson.M{consts.UserId: userId}
+	sort, err := p.MakeSortOptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var data []*Folder
+	if err = m.conn.Find(ctx, &data, filter, &options.FindOptions{
+		Sort:  sort,
+		Limit: popts.Limit,
+		Skip:  popts.Offset,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 如果是反向查询，反转数据
+	if *popts.Backward {
+		for i := 0; i < len(data)/2; i++ {
+			data[i], data[len(data)-i-1] = data[len(data)-i-1], data[i]
+		}
+	}
+	if len(data) > 0 {
+		err = p.StoreCursor(ctx, data[0], data[len(data)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (m *FolderMongoMapper) Count(ctx context.Context, userId string) (int64, error) {
+	return m.conn.CountDocuments(ctx, bson.M{consts.UserId: userId})
+}
+
+func (m *FolderMongoMapper) FindManyAndCount(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Folder, int64, error) {
+	var data []*Folder
+	var total int64
+	if err := mr.Finish(func() error {
+		var err error
+		data, err = m.FindMany(ctx, userId, popts, sorter)
+		return err
+	}, func() error {
+		var err error
+		total, err = m.Count(ctx, userId)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
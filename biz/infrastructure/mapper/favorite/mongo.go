@@ -0,0 +1,174 @@
+package favorite
+
+import (
+	"context"
+	"time"
+
+	"github.com/xh-polaris/gopkg/pagination"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/zeromicro/go-zero/core/mr"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixFavoriteCacheKey = "cache:favorite:"
+const CollectionName = "favorite"
+
+var _ IMongoMapper = (*MongoMapper)(nil)
+
+type (
+	IMongoMapper interface {
+		Insert(ctx context.Context, data *Favorite) error
+		Delete(ctx context.Context, folderId string, targetId string, targetType int64) (bool, error)
+		DeleteByFolder(ctx context.Context, folderId string) (int64, error)
+		FindMany(ctx context.Context, folderId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Favorite, error)
+		Count(ctx context.Context, folderId string) (int64, error)
+		FindManyAndCount(ctx context.Context, folderId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Favorite, int64, error)
+		GetSavedTargets(ctx context.Context, userId string, targetIds []string, targetType int64) ([]string, error)
+	}
+
+	MongoMapper struct {
+		conn *monc.Model
+	}
+
+	// Favorite 收藏夹中的一个目标，同一目标可以被收藏到同一用户的多个收藏夹中
+	Favorite struct {
+		ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+		UserId     string             `bson:"userId,omitempty" json:"userId,omitempty"`
+		FolderId   string             `bson:"folderId,omitempty" json:"folderId,omitempty"`
+		TargetId   string             `bson:"targetId,omitempty" json:"targetId,omitempty"`
+		TargetType int64              `bson:"targetType,omitempty" json:"targetType,omitempty"`
+		CreateAt   time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+	}
+)
+
+func NewMongoMapper(config *config.Config) IMongoMapper {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.CacheConf)
+	_, err := conn.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// 同一收藏夹中不能重复收藏同一目标
+		{
+			Keys: bson.D{
+				{Key: consts.FolderId, Value: 1},
+				{Key: consts.TargetId, Value: 1},
+				{Key: consts.TargetType, Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		// 批量查询用户是否收藏过目标
+		{
+			Keys: bson.D{
+				{Key: consts.UserId, Value: 1},
+				{Key: consts.TargetType, Value: 1},
+				{Key: consts.TargetId, Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		log.Error("create favorite index fail, err=%v", err)
+	}
+	return &MongoMapper{
+		conn: conn,
+	}
+}
+
+func (m *MongoMapper) Insert(ctx context.Context, data *Favorite) error {
+	if data.ID.IsZero() {
+		data.ID = primitive.NewObjectID()
+		data.CreateAt = time.Now()
+	}
+
+	key := prefixFavoriteCacheKey + data.ID.Hex()
+	_, err := m.conn.InsertOne(ctx, key, data)
+	return err
+}
+
+// Delete 从收藏夹中移除目标，返回目标是否在收藏夹中
+func (m *MongoMapper) Delete(ctx context.Context, folderId string, targetId string, targetType int64) (bool, error) {
+	n, err := m.conn.DeleteOneNoCache(ctx, bson.M{consts.FolderId: folderId, consts.TargetId: targetId, consts.TargetType: targetType})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (m *MongoMapper) DeleteByFolder(ctx context.Context, folderId string) (int64, error) {
+	return m.conn.DeleteMany(ctx, bson.M{consts.FolderId: folderId})
+}
+
+func (m *MongoMapper) FindMany(ctx context.Context, folderId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Favorite, error) {
+	p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
+	filter := bson.M{consts.FolderId: folderId}
+	sort, err := p.MakeSortOptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var data []*Favorite
+	if err = m.conn.Find(ctx, &data, filter, &options.FindOptions{
+		Sort:  sort,
+		Limit: popts.Limit,
+		Skip:  popts.Offset,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 如果是反向查询，反转数据
+	if *popts.Backward {
+		for i := 0; i < len(data)/2; i++ {
+			data[i], data[len(data)-i-1] = data[len(data)-i-1], data[i]
+		}
+	}
+	if len(data) > 0 {
+		err = p.StoreCursor(ctx, data[0], data[len(data)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (m *MongoMapper) Count(ctx context.Context, folderId string) (int64, error) {
+	return m.conn.CountDocuments(ctx, bson.M{consts.FolderId: folderId})
+}
+
+func (m *MongoMapper) FindManyAndCount(ctx context.Context, folderId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Favorite, int64, error) {
+	var data []*Favorite
+	var total int64
+	if err := mr.Finish(func() error {
+		var err error
+		data, err = m.FindMany(ctx, folderId, popts, sorter)
+		return err
+	}, func() error {
+		var err error
+		total, err = m.Count(ctx, folderId)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// GetSavedTargets 返回targetIds中被用户收藏到任一收藏夹的目标
+func (m *MongoMapper) GetSavedTargets(ctx context.Context, userId string, targetIds []string, targetType int64) ([]string, error) {
+	res, err := m.conn.Distinct(ctx, consts.TargetId, bson.M{
+		consts.UserId:     userId,
+		consts.TargetType: targetType,
+		consts.TargetId:   bson.M{"$in": targetIds},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := make([]string, 0, len(res))
+	for _, id := range res {
+		if s, ok := id.(string); ok {
+			data = append(data, s)
+		}
+	}
+	return data, nil
+}
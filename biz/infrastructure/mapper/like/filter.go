@@ -1,13 +1,24 @@
 package like
 
 import (
+	"time"
+
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// FilterOptions 同时设置单值和集合条件时，单值条件优先
 type FilterOptions struct {
-	OnlyTargetId   *string
-	OnlyTargetType *int32
+	OnlyTargetId     *string
+	OnlyTargetType   *int32
+	OnlyTargetTypes  []int32
+	OnlyUserId       *string
+	OnlyUserIds      []string
+	OnlyAssociatedId *string
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	// IncludeDeleted 为true时包含已取消的点赞
+	IncludeDeleted bool
 }
 
 type MongoFilter struct {
@@ -24,7 +35,13 @@ func makeMongoFilter(options *FilterOptions) bson.M {
 
 func (f *MongoFilter) toBson() bson.M {
 	f.CheckOnlyTargetId()
+	f.CheckOnlyTargetTypes()
 	f.CheckOnlyTargetType()
+	f.CheckOnlyUserIds()
+	f.CheckOnlyUserId()
+	f.CheckOnlyAssociatedId()
+	f.CheckCreateAtRange()
+	f.CheckIncludeDeleted()
 	return f.m
 }
 
@@ -40,6 +57,49 @@ func (f *MongoFilter) CheckOnlyTargetType() {
 	}
 }
 
+func (f *MongoFilter) CheckOnlyTargetTypes() {
+	if f.OnlyTargetTypes != nil {
+		f.m[consts.TargetType] = bson.M{"$in": f.OnlyTargetTypes}
+	}
+}
+
+func (f *MongoFilter) CheckOnlyUserId() {
+	if f.OnlyUserId != nil {
+		f.m[consts.UserId] = *f.OnlyUserId
+	}
+}
+
+func (f *MongoFilter) CheckOnlyAssociatedId() {
+	if f.OnlyAssociatedId != nil {
+		f.m[consts.AssociatedId] = *f.OnlyAssociatedId
+	}
+}
+
+func (f *MongoFilter) CheckCreateAtRange() {
+	r := bson.M{}
+	if f.CreatedAfter != nil {
+		r["$gte"] = *f.CreatedAfter
+	}
+	if f.CreatedBefore != nil {
+		r["$lt"] = *f.CreatedBefore
+	}
+	if len(r) > 0 {
+		f.m[consts.CreateAt] = r
+	}
+}
+
+func (f *MongoFilter) CheckOnlyUserIds() {
+	if f.OnlyUserIds != nil {
+		f.m[consts.UserId] = bson.M{"$in": f.OnlyUserIds}
+	}
+}
+
+func (f *MongoFilter) CheckIncludeDeleted() {
+	if !f.IncludeDeleted {
+		f.m[consts.DeletedAt] = notDeleted
+	}
+}
+
 //
 //type EsFilter struct {
 //	q []types.Query
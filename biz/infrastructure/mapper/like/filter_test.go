@@ -0,0 +1,78 @@
+package like
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+)
+
+func TestMakeMongoFilter(t *testing.T) {
+	targetId := "target"
+	userId := "user"
+	targetType := int32(1)
+	after := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts *FilterOptions
+		want bson.M
+	}{
+		{
+			name: "deleted excluded by default",
+			opts: &FilterOptions{},
+			want: bson.M{consts.DeletedAt: notDeleted},
+		},
+		{
+			name: "include deleted",
+			opts: &FilterOptions{OnlyTargetId: &targetId, IncludeDeleted: true},
+			want: bson.M{consts.TargetId: targetId},
+		},
+		{
+			name: "single target type takes priority over set",
+			opts: &FilterOptions{OnlyTargetType: &targetType, OnlyTargetTypes: []int32{2, 3}},
+			want: bson.M{consts.TargetType: targetType, consts.DeletedAt: notDeleted},
+		},
+		{
+			name: "single user takes priority over set",
+			opts: &FilterOptions{OnlyUserId: &userId, OnlyUserIds: []string{"a", "b"}},
+			want: bson.M{consts.UserId: userId, consts.DeletedAt: notDeleted},
+		},
+		{
+			name: "sets used when no single value",
+			opts: &FilterOptions{OnlyTargetTypes: []int32{2, 3}, OnlyUserIds: []string{"a", "b"}},
+			want: bson.M{
+				consts.TargetType: bson.M{"$in": []int32{2, 3}},
+				consts.UserId:     bson.M{"$in": []string{"a", "b"}},
+				consts.DeletedAt:  notDeleted,
+			},
+		},
+		{
+			name: "create at range merged",
+			opts: &FilterOptions{CreatedAfter: &after, CreatedBefore: &before},
+			want: bson.M{
+				consts.CreateAt:  bson.M{"$gte": after, "$lt": before},
+				consts.DeletedAt: notDeleted,
+			},
+		},
+		{
+			name: "create at lower bound only",
+			opts: &FilterOptions{CreatedAfter: &after},
+			want: bson.M{
+				consts.CreateAt:  bson.M{"$gte": after},
+				consts.DeletedAt: notDeleted,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := makeMongoFilter(tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("makeMongoFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
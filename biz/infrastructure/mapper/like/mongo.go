@@ -8,18 +8,49 @@ import (
 
 	"github.com/xh-polaris/gopkg/pagination"
 	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/xh-polaris/service-idl-gen-go/kitex_gen/meowchat/user"
 	"github.com/zeromicro/go-zero/core/stores/monc"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
 )
 
 const prefixLikeCacheKey = "cache:like:"
 const CollectionName = "like"
 
+// 旧版本的唯一索引，不包含deletedAt
+const legacyUniqueIndex = "userId_1_targetId_1_targetType_1"
+
+// notDeleted 只匹配未取消的点赞
+var notDeleted = bson.M{"$exists": false}
+
+// Reaction 点赞的表态，每个用户对同一目标只能有一种表态
+type Reaction string
+
+const (
+	// ReactionLike 默认表态，未记录表态的旧点赞也视为此表态
+	ReactionLike  Reaction = "like"
+	ReactionPurr  Reaction = "purr"
+	ReactionHeart Reaction = "heart"
+	ReactionLaugh Reaction = "laugh"
+)
+
+var Reactions = []Reaction{ReactionLike, ReactionPurr, ReactionHeart, ReactionLaugh}
+
+func IsValidReaction(r Reaction) bool {
+	for _, reaction := range Reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
 var _ IMongoMapper = (*MongoMapper)(nil)
 
 type (
@@ -28,15 +59,29 @@ type (
 		FindOne(ctx context.Context, id string) (*Like, error)
 		Update(ctx context.Context, data *Like) error
 		Delete(ctx context.Context, id string) error
+		SoftDelete(ctx context.Context, id string) (bool, error)
+		UpdateReaction(ctx context.Context, id string, reaction Reaction) error
+		FindTargetLikes(ctx context.Context, targetId string, targetType int64, limit int64) ([]*Like, error)
 		FindMany(ctx context.Context, fopts *FilterOptions, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, error)
 		Count(ctx context.Context, filter *FilterOptions) (int64, error)
 		FindManyAndCount(ctx context.Context, fopts *FilterOptions, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error)
 		GetUserLike(ctx context.Context, userId string, targetId string, targetType int64) error
+		GetUserLikedTargets(ctx context.Context, userId string, targetIds []string, targetType int64) ([]string, error)
+		ListUserLikedTargets(ctx context.Context, userId string, targetType int64, limit int64) ([]string, error)
+		ListUserTargets(ctx context.Context, userId string, limit int64) ([]*Target, error)
+		CountTargets(ctx context.Context, targets []Target) ([]*TargetCount, error)
+		CountReactions(ctx context.Context, targetId string, targetType int64) ([]*ReactionCount, error)
+		CountByAssociatedId(ctx context.Context, associatedId string, targetType *int64, limit int64) (int64, []*TargetCount, error)
+		CountTrending(ctx context.Context, targetType int64, since time.Time, now time.Time, gravity float64, limit int64) ([]*TargetScore, error)
+		FindFolloweesWhoLike(ctx context.Context, userId string, targetId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error)
+		FindCommonLikes(ctx context.Context, userId string, otherId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error)
 		GetUserLikes(ctx context.Context, userId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error)
 		FindUserLikes(ctx context.Context, userId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, error)
 		CountUserLikes(ctx context.Context, userId string, targetType int64) (int64, error)
-		GetTargetLikes(ctx context.Context, targetId string, targetType int64) ([]*Like, error)
 		GetId(ctx context.Context, userId string, targetId string, targetType int64) (string, error)
+		FindUserLike(ctx context.Context, userId string, targetId string, targetType int64) (*Like, error)
+		CountLikedUsers(ctx context.Context, since time.Time, limit int64) ([]*UserCount, error)
+		CountLikedUser(ctx context.Context, likedUserId string, since time.Time) (int64, error)
 	}
 
 	MongoMapper struct {
@@ -49,18 +94,165 @@ type (
 		TargetId     string             `bson:"targetId,omitempty" json:"targetId,omitempty"`
 		TargetType   int64              `bson:"targetType,omitempty" json:"targetType,omitempty"`
 		AssociatedId string             `bson:"associatedId,omitempty" json:"associatedId,omitempty"`
+		LikedUserId  string             `bson:"likedUserId,omitempty" json:"likedUserId,omitempty"`
+		Reaction     Reaction           `bson:"reaction,omitempty" json:"reaction,omitempty"`
 		UpdateAt     time.Time          `bson:"updateAt,omitempty" json:"updateAt,omitempty"`
 		CreateAt     time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+		DeletedAt    time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	}
+
+	Target struct {
+		TargetId   string `bson:"targetId" json:"targetId"`
+		TargetType int64  `bson:"targetType" json:"targetType"`
+	}
+
+	TargetCount struct {
+		Target `bson:"_id"`
+		Count  int64 `bson:"count"`
+	}
+
+	ReactionCount struct {
+		Reaction Reaction `bson:"_id"`
+		Count    int64    `bson:"count"`
+	}
+
+	UserCount struct {
+		UserId string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+
+	TargetScore struct {
+		TargetId string  `bson:"_id"`
+		Score    float64 `bson:"score"`
 	}
 )
 
 func NewMongoModel(config *config.Config) IMongoMapper {
 	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.CacheConf)
+	// 唯一索引无法建立时不启动服务，否则会继续产生重复的点赞
+	if err := ensureUniqueIndex(context.Background(), conn); err != nil {
+		panic(err)
+	}
+	// 新的唯一索引建立后再删除旧索引，保证任意时刻都有唯一约束
+	_, err := conn.Indexes().DropOne(context.Background(), legacyUniqueIndex)
+	if err != nil {
+		if ce, ok := err.(mongo.CommandError); !ok || ce.Name != "IndexNotFound" {
+			log.Error("drop legacy like index fail, err=%v", err)
+		}
+	}
+	_, err = conn.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// 按目标查询点赞用户，以及在指定用户范围内统计目标的点赞
+		{
+			Keys: bson.D{
+				{Key: consts.TargetId, Value: 1},
+				{Key: consts.TargetType, Value: 1},
+				{Key: consts.UserId, Value: 1},
+			},
+		},
+		// 用户跨类型的点赞时间线，_id与createAt同时生成，按_id排序即按点赞时间排序
+		{
+			Keys: bson.D{
+				{Key: consts.UserId, Value: 1},
+				{Key: consts.ID, Value: -1},
+			},
+		},
+		// 按时间窗口统计热门目标
+		{
+			Keys: bson.D{
+				{Key: consts.TargetType, Value: 1},
+				{Key: consts.CreateAt, Value: 1},
+			},
+		},
+		// 统计用户收到的点赞
+		{
+			Keys: bson.D{
+				{Key: consts.LikedUserId, Value: 1},
+				{Key: consts.CreateAt, Value: 1},
+			},
+		},
+		// 按关联的父级目标统计点赞
+		{
+			Keys: bson.D{
+				{Key: consts.AssociatedId, Value: 1},
+				{Key: consts.TargetType, Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		log.Error("create like index fail, err=%v", err)
+	}
 	return &MongoMapper{
 		conn: conn,
 	}
 }
 
+// uniqueIndex 保证同一用户对同一目标只有一条未取消的点赞记录，未取消的记录deletedAt均视为null
+var uniqueIndex = mongo.IndexModel{
+	Keys: bson.D{
+		{Key: consts.UserId, Value: 1},
+		{Key: consts.TargetId, Value: 1},
+		{Key: consts.TargetType, Value: 1},
+		{Key: consts.DeletedAt, Value: 1},
+	},
+	Options: options.Index().SetUnique(true),
+}
+
+// ensureUniqueIndex 建立唯一索引，已有重复的点赞导致建立失败时先取消重复的点赞再重试
+func ensureUniqueIndex(ctx context.Context, conn *monc.Model) error {
+	_, err := conn.Indexes().CreateOne(ctx, uniqueIndex)
+	if err == nil || !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	n, err := dedupeLikes(ctx, conn)
+	if err != nil {
+		return err
+	}
+	log.Info("dedupe likes before creating unique index, canceled=%d", n)
+	_, err = conn.Indexes().CreateOne(ctx, uniqueIndex)
+	return err
+}
+
+// dedupeLikes 同一用户对同一目标有多条未取消的点赞时只保留最早的一条，其余标记为已取消，返回取消的条数
+// 每条记录使用不同的deletedAt以免与唯一索引冲突，点赞计数器的偏差由校对任务修正
+func dedupeLikes(ctx context.Context, conn *monc.Model) (int64, error) {
+	var data []struct {
+		Ids []primitive.ObjectID `bson:"ids"`
+	}
+	err := conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": bson.M{consts.DeletedAt: notDeleted}},
+		bson.M{"$sort": bson.M{consts.ID: 1}},
+		bson.M{"$group": bson.M{
+			consts.ID: bson.M{
+				consts.UserId:     "$" + consts.UserId,
+				consts.TargetId:   "$" + consts.TargetId,
+				consts.TargetType: "$" + consts.TargetType,
+			},
+			"ids":        bson.M{"$push": "$" + consts.ID},
+			consts.Count: bson.M{"$sum": 1},
+		}},
+		bson.M{"$match": bson.M{consts.Count: bson.M{"$gt": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	var n int64
+	for _, d := range data {
+		for i, id := range d.Ids[1:] {
+			deletedAt := now.Add(time.Duration(i) * time.Millisecond)
+			res, err := conn.UpdateOne(ctx, prefixLikeCacheKey+id.Hex(), bson.M{consts.ID: id, consts.DeletedAt: notDeleted}, bson.M{"$set": bson.M{
+				consts.DeletedAt: deletedAt,
+				consts.UpdateAt:  now,
+			}})
+			if err != nil {
+				return n, err
+			}
+			n += res.ModifiedCount
+		}
+	}
+	return n, nil
+}
+
 func (m *MongoMapper) GetUserLikes(ctx context.Context, userId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error) {
 	var data []*Like
 	var total int64
@@ -100,7 +292,7 @@ func (m *MongoMapper) GetUserLikes(ctx context.Context, userId string, targetTyp
 func (m *MongoMapper) FindUserLikes(ctx context.Context, userId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, error) {
 	p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
 
-	filter := bson.M{consts.UserId: userId, consts.TargetType: targetType}
+	filter := bson.M{consts.UserId: userId, consts.TargetType: targetType, consts.DeletedAt: notDeleted}
 	sort, err := p.MakeSortOptions(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -131,25 +323,63 @@ func (m *MongoMapper) FindUserLikes(ctx context.Context, userId string, targetTy
 }
 
 func (m *MongoMapper) CountUserLikes(ctx context.Context, userId string, targetType int64) (int64, error) {
-	f := bson.M{consts.UserId: userId, consts.TargetType: targetType}
+	f := bson.M{consts.UserId: userId, consts.TargetType: targetType, consts.DeletedAt: notDeleted}
 	return m.conn.CountDocuments(ctx, f)
 }
 
 func (m *MongoMapper) GetId(ctx context.Context, userId string, targetId string, targetType int64) (id string, err error) {
 	like := Like{}
-	err = m.conn.FindOneNoCache(ctx, &like, bson.M{consts.UserId: userId, consts.TargetId: targetId, consts.TargetType: targetType})
+	err = m.conn.FindOneNoCache(ctx, &like, bson.M{consts.UserId: userId, consts.TargetId: targetId, consts.TargetType: targetType, consts.DeletedAt: notDeleted})
 	id = like.ID.Hex()
 	return
 }
 
-func (m *MongoMapper) GetTargetLikes(ctx context.Context, targetId string, targetType int64) ([]*Like, error) {
-	data := make([]*Like, 0)
-	err := m.conn.Find(ctx, &data, bson.M{consts.TargetId: targetId, consts.TargetType: targetType})
+func (m *MongoMapper) FindUserLike(ctx context.Context, userId string, targetId string, targetType int64) (*Like, error) {
+	var data Like
+	err := m.conn.FindOneNoCache(ctx, &data, bson.M{consts.UserId: userId, consts.TargetId: targetId, consts.TargetType: targetType, consts.DeletedAt: notDeleted})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CountLikedUser 统计用户自since起内容获得的点赞数，since为零值时统计全部，关注不计入
+func (m *MongoMapper) CountLikedUser(ctx context.Context, likedUserId string, since time.Time) (int64, error) {
+	filter := bson.M{
+		consts.LikedUserId: likedUserId,
+		consts.TargetType:  bson.M{"$ne": int64(user.LikeType_User)},
+		consts.DeletedAt:   notDeleted,
+	}
+	if !since.IsZero() {
+		filter[consts.CreateAt] = bson.M{"$gte": since}
+	}
+	return m.conn.CountDocuments(ctx, filter)
+}
+
+// CountLikedUsers 统计自since起内容获得点赞最多的至多limit个用户，since为零值时统计全部，关注不计入
+func (m *MongoMapper) CountLikedUsers(ctx context.Context, since time.Time, limit int64) ([]*UserCount, error) {
+	match := bson.M{
+		consts.LikedUserId: bson.M{"$exists": true, "$ne": ""},
+		consts.TargetType:  bson.M{"$ne": int64(user.LikeType_User)},
+		consts.DeletedAt:   notDeleted,
+	}
+	if !since.IsZero() {
+		match[consts.CreateAt] = bson.M{"$gte": since}
+	}
+	data := make([]*UserCount, 0, limit)
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			consts.ID:    "$" + consts.LikedUserId,
+			consts.Count: bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.D{{Key: consts.Count, Value: -1}, {Key: consts.ID, Value: 1}}},
+		bson.M{"$limit": limit},
+	})
 	if err != nil {
 		return nil, err
-	} else {
-		return data, nil
 	}
+	return data, nil
 }
 
 func (m *MongoMapper) FindMany(ctx context.Context, fopts *FilterOptions, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, error) {
@@ -207,10 +437,261 @@ func (m *MongoMapper) Count(ctx context.Context, filter *FilterOptions) (int64,
 
 func (m *MongoMapper) GetUserLike(ctx context.Context, userId string, targetId string, targetType int64) (err error) {
 	like := Like{}
-	err = m.conn.FindOneNoCache(ctx, &like, bson.M{consts.UserId: userId, consts.TargetId: targetId, consts.TargetType: targetType})
+	err = m.conn.FindOneNoCache(ctx, &like, bson.M{consts.UserId: userId, consts.TargetId: targetId, consts.TargetType: targetType, consts.DeletedAt: notDeleted})
 	return
 }
 
+// GetUserLikedTargets 返回targetIds中用户已点赞的部分
+func (m *MongoMapper) GetUserLikedTargets(ctx context.Context, userId string, targetIds []string, targetType int64) ([]string, error) {
+	data := make([]*Like, 0, len(targetIds))
+	err := m.conn.Find(ctx, &data, bson.M{
+		consts.UserId:     userId,
+		consts.TargetId:   bson.M{"$in": targetIds},
+		consts.TargetType: targetType,
+		consts.DeletedAt:  notDeleted,
+	}, options.Find().SetProjection(bson.M{consts.TargetId: 1}))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(data))
+	for _, alike := range data {
+		ids = append(ids, alike.TargetId)
+	}
+	return ids, nil
+}
+
+// ListUserLikedTargets 按时间倒序返回用户最近点赞的至多limit个目标
+func (m *MongoMapper) ListUserLikedTargets(ctx context.Context, userId string, targetType int64, limit int64) ([]string, error) {
+	data := make([]*Like, 0)
+	err := m.conn.Find(ctx, &data, bson.M{
+		consts.UserId:     userId,
+		consts.TargetType: targetType,
+		consts.DeletedAt:  notDeleted,
+	}, options.Find().
+		SetProjection(bson.M{consts.TargetId: 1}).
+		SetSort(bson.M{consts.ID: -1}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(data))
+	for _, alike := range data {
+		ids = append(ids, alike.TargetId)
+	}
+	return ids, nil
+}
+
+// CountTargets 通过一次聚合统计多个目标的点赞数，没有点赞的目标不会出现在结果中
+func (m *MongoMapper) CountTargets(ctx context.Context, targets []Target) ([]*TargetCount, error) {
+	or := make(bson.A, 0, len(targets))
+	for _, t := range targets {
+		or = append(or, bson.M{consts.TargetId: t.TargetId, consts.TargetType: t.TargetType})
+	}
+	data := make([]*TargetCount, 0, len(targets))
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": bson.M{"$or": or, consts.DeletedAt: notDeleted}},
+		bson.M{"$group": bson.M{
+			consts.ID: bson.M{
+				consts.TargetId:   "$" + consts.TargetId,
+				consts.TargetType: "$" + consts.TargetType,
+			},
+			consts.Count: bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CountReactions 按表态分别统计目标的点赞数
+func (m *MongoMapper) CountReactions(ctx context.Context, targetId string, targetType int64) ([]*ReactionCount, error) {
+	data := make([]*ReactionCount, 0, len(Reactions))
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": bson.M{consts.TargetId: targetId, consts.TargetType: targetType, consts.DeletedAt: notDeleted}},
+		bson.M{"$group": bson.M{
+			consts.ID:    bson.M{"$ifNull": bson.A{"$" + consts.Reaction, ReactionLike}},
+			consts.Count: bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CountByAssociatedId 统计关联到associatedId的所有点赞数，以及点赞数最多的至多limit个目标
+func (m *MongoMapper) CountByAssociatedId(ctx context.Context, associatedId string, targetType *int64, limit int64) (int64, []*TargetCount, error) {
+	filter := bson.M{consts.AssociatedId: associatedId, consts.DeletedAt: notDeleted}
+	if targetType != nil {
+		filter[consts.TargetType] = *targetType
+	}
+	var data []struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Targets []*TargetCount `bson:"targets"`
+	}
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$facet": bson.M{
+			"total": bson.A{bson.M{"$count": consts.Count}},
+			"targets": bson.A{
+				bson.M{"$group": bson.M{
+					consts.ID: bson.M{
+						consts.TargetId:   "$" + consts.TargetId,
+						consts.TargetType: "$" + consts.TargetType,
+					},
+					consts.Count: bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.D{{Key: consts.Count, Value: -1}, {Key: consts.ID, Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+		}},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) == 0 {
+		return 0, []*TargetCount{}, nil
+	}
+	var total int64
+	if len(data[0].Total) > 0 {
+		total = data[0].Total[0].Count
+	}
+	return total, data[0].Targets, nil
+}
+
+// ListUserTargets 返回用户点赞过的所有类型的目标，至多limit个
+func (m *MongoMapper) ListUserTargets(ctx context.Context, userId string, limit int64) ([]*Target, error) {
+	data := make([]*Target, 0)
+	if err := m.conn.Find(ctx, &data, bson.M{consts.UserId: userId, consts.DeletedAt: notDeleted}, &options.FindOptions{
+		Projection: bson.M{consts.ID: 0, consts.TargetId: 1, consts.TargetType: 1},
+		Limit:      &limit,
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CountTrending 统计since之后获得点赞最多的至多limit个目标
+// gravity大于0时每个点赞的权重为1/(距今小时数+2)^gravity，否则权重为1
+func (m *MongoMapper) CountTrending(ctx context.Context, targetType int64, since time.Time, now time.Time, gravity float64, limit int64) ([]*TargetScore, error) {
+	var weight any = 1
+	if gravity > 0 {
+		hours := bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{now, "$" + consts.CreateAt}}, time.Hour.Milliseconds()}}
+		weight = bson.M{"$divide": bson.A{1, bson.M{"$pow": bson.A{bson.M{"$add": bson.A{hours, 2}}, gravity}}}}
+	}
+	data := make([]*TargetScore, 0, limit)
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": bson.M{
+			consts.TargetType: targetType,
+			consts.CreateAt:   bson.M{"$gte": since},
+			consts.DeletedAt:  notDeleted,
+		}},
+		bson.M{"$group": bson.M{
+			consts.ID: "$" + consts.TargetId,
+			"score":   bson.M{"$sum": weight},
+		}},
+		bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: consts.ID, Value: 1}}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindFolloweesWhoLike 分页查询userId关注的用户中点赞过目标的关注记录
+func (m *MongoMapper) FindFolloweesWhoLike(ctx context.Context, userId string, targetId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error) {
+	return m.aggregateMatchedLikes(ctx, bson.M{consts.UserId: userId, consts.TargetType: int64(user.LikeType_User), consts.DeletedAt: notDeleted}, bson.M{
+		consts.TargetId:   targetId,
+		consts.TargetType: targetType,
+	}, consts.UserId, popts, sorter)
+}
+
+// FindCommonLikes 分页查询userId的点赞中otherId也点赞过的记录
+func (m *MongoMapper) FindCommonLikes(ctx context.Context, userId string, otherId string, targetType int64, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error) {
+	return m.aggregateMatchedLikes(ctx, bson.M{consts.UserId: userId, consts.TargetType: targetType, consts.DeletedAt: notDeleted}, bson.M{
+		consts.UserId:     otherId,
+		consts.TargetType: targetType,
+	}, consts.TargetId, popts, sorter)
+}
+
+// aggregateMatchedLikes 分页查询满足filter且在like集合中能找到匹配记录的点赞，匹配记录满足lookup且correlated字段等于当前记录的targetId
+// 调用方传入的值只放在普通的$match条件中，避免以$开头的值在$expr中被当作字段路径
+func (m *MongoMapper) aggregateMatchedLikes(ctx context.Context, filter bson.M, lookup bson.M, correlated string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Like, int64, error) {
+	match := bson.M{
+		"$expr":          bson.M{"$eq": bson.A{"$" + correlated, "$$" + consts.TargetId}},
+		consts.DeletedAt: notDeleted,
+	}
+	for k, v := range lookup {
+		match[k] = v
+	}
+	lookupStages := bson.A{
+		bson.M{"$lookup": bson.M{
+			"from": CollectionName,
+			"let":  bson.M{consts.TargetId: "$" + consts.TargetId},
+			"pipeline": bson.A{
+				bson.M{"$match": match},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{consts.ID: 1}},
+			},
+			"as": "matched",
+		}},
+		bson.M{"$match": bson.M{"matched": bson.M{"$ne": bson.A{}}}},
+		bson.M{"$project": bson.M{"matched": 0}},
+	}
+
+	countFilter := bson.M{}
+	for k, v := range filter {
+		countFilter[k] = v
+	}
+	var data []*Like
+	var total int64
+	if err := mr.Finish(func() error {
+		p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
+		sort, err := p.MakeSortOptions(ctx, filter)
+		if err != nil {
+			return err
+		}
+		pipeline := bson.A{bson.M{"$match": filter}, bson.M{"$sort": sort}}
+		pipeline = append(pipeline, lookupStages...)
+		pipeline = append(pipeline, bson.M{"$skip": *popts.Offset}, bson.M{"$limit": *popts.Limit})
+		if err = m.conn.Aggregate(ctx, &data, pipeline); err != nil {
+			return err
+		}
+
+		// 如果是反向查询，反转数据
+		if *popts.Backward {
+			for i := 0; i < len(data)/2; i++ {
+				data[i], data[len(data)-i-1] = data[len(data)-i-1], data[i]
+			}
+		}
+		if len(data) > 0 {
+			return p.StoreCursor(ctx, data[0], data[len(data)-1])
+		}
+		return nil
+	}, func() error {
+		pipeline := bson.A{bson.M{"$match": countFilter}}
+		pipeline = append(pipeline, lookupStages...)
+		pipeline = append(pipeline, bson.M{"$count": consts.Count})
+		var res []struct {
+			Count int64 `bson:"count"`
+		}
+		if err := m.conn.Aggregate(ctx, &res, pipeline); err != nil {
+			return err
+		}
+		if len(res) > 0 {
+			total = res[0].Count
+		}
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
 func (m *MongoMapper) Insert(ctx context.Context, data *Like) error {
 	if data.ID.IsZero() {
 		data.ID = primitive.NewObjectID()
@@ -258,3 +739,60 @@ func (m *MongoMapper) Delete(ctx context.Context, id string) error {
 	_, err = m.conn.DeleteOne(ctx, key, bson.M{consts.ID: oid})
 	return err
 }
+
+// UpdateReaction 切换未取消的点赞的表态
+func (m *MongoMapper) UpdateReaction(ctx context.Context, id string, reaction Reaction) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return consts.ErrInvalidObjectId
+	}
+	key := prefixLikeCacheKey + id
+	_, err = m.conn.UpdateOne(ctx, key, bson.M{consts.ID: oid, consts.DeletedAt: notDeleted}, bson.M{"$set": bson.M{
+		consts.Reaction: reaction,
+		consts.UpdateAt: time.Now(),
+	}})
+	return err
+}
+
+// FindTargetLikes 返回目标自身以及关联到该目标的至多limit个未取消的点赞
+func (m *MongoMapper) FindTargetLikes(ctx context.Context, targetId string, targetType int64, limit int64) ([]*Like, error) {
+	data := make([]*Like, 0, limit)
+	if err := m.conn.Find(ctx, &data, bson.M{
+		"$or": bson.A{
+			bson.M{consts.TargetId: targetId, consts.TargetType: targetType},
+			bson.M{consts.AssociatedId: targetId},
+		},
+		consts.DeletedAt: notDeleted,
+	}, &options.FindOptions{
+		Limit: &limit,
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SoftDelete 标记点赞为已取消，保留记录用于点赞历史，点赞已被取消时返回false
+func (m *MongoMapper) SoftDelete(ctx context.Context, id string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, consts.ErrInvalidObjectId
+	}
+	now := time.Now()
+	key := prefixLikeCacheKey + id
+	res, err := m.conn.UpdateOne(ctx, key, bson.M{consts.ID: oid, consts.DeletedAt: notDeleted}, bson.M{"$set": bson.M{
+		consts.DeletedAt: now,
+		consts.UpdateAt:  now,
+	}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// GetReaction 返回点赞的表态，未记录表态时为默认表态
+func (l *Like) GetReaction() Reaction {
+	if l.Reaction == "" {
+		return ReactionLike
+	}
+	return l.Reaction
+}
@@ -0,0 +1,146 @@
+package reward
+
+import (
+	"context"
+	"time"
+
+	"github.com/xh-polaris/gopkg/pagination"
+	"github.com/xh-polaris/gopkg/pagination/mongop"
+	"github.com/zeromicro/go-zero/core/mr"
+	"github.com/zeromicro/go-zero/core/stores/monc"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/consts"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util/log"
+)
+
+const prefixRewardCacheKey = "cache:reward:"
+const CollectionName = "reward"
+
+const (
+	ReasonLike = "like"
+)
+
+var _ IMongoMapper = (*MongoMapper)(nil)
+
+type (
+	IMongoMapper interface {
+		Insert(ctx context.Context, data *Reward) error
+		FindMany(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Reward, error)
+		Count(ctx context.Context, userId string) (int64, error)
+		FindManyAndCount(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Reward, int64, error)
+		SumAmount(ctx context.Context, userId string, since time.Time) (int64, error)
+	}
+
+	MongoMapper struct {
+		conn *monc.Model
+	}
+
+	// Reward 一次小鱼干发放记录
+	Reward struct {
+		ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+		UserId   string             `bson:"userId,omitempty" json:"userId,omitempty"`
+		LikeId   string             `bson:"likeId,omitempty" json:"likeId,omitempty"`
+		Amount   int64              `bson:"amount,omitempty" json:"amount,omitempty"`
+		Reason   string             `bson:"reason,omitempty" json:"reason,omitempty"`
+		CreateAt time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
+	}
+)
+
+func NewMongoMapper(config *config.Config) IMongoMapper {
+	conn := monc.MustNewModel(config.Mongo.URL, config.Mongo.DB, CollectionName, config.CacheConf)
+	_, err := conn.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: consts.UserId, Value: 1}, {Key: consts.CreateAt, Value: 1}},
+	})
+	if err != nil {
+		log.Error("create reward index fail, err=%v", err)
+	}
+	return &MongoMapper{
+		conn: conn,
+	}
+}
+
+func (m *MongoMapper) Insert(ctx context.Context, data *Reward) error {
+	if data.ID.IsZero() {
+		data.ID = primitive.NewObjectID()
+		data.CreateAt = time.Now()
+	}
+
+	key := prefixRewardCacheKey + data.ID.Hex()
+	_, err := m.conn.InsertOne(ctx, key, data)
+	return err
+}
+
+func (m *MongoMapper) FindMany(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Reward, error) {
+	p := mongop.NewMongoPaginator(pagination.NewRawStore(sorter), popts)
+	filter := bson.M{consts.UserId: userId}
+	sort, err := p.MakeSortOptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var data []*Reward
+	if err = m.conn.Find(ctx, &data, filter, &options.FindOptions{
+		Sort:  sort,
+		Limit: popts.Limit,
+		Skip:  popts.Offset,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 如果是反向查询，反转数据
+	if *popts.Backward {
+		for i := 0; i < len(data)/2; i++ {
+			data[i], data[len(data)-i-1] = data[len(data)-i-1], data[i]
+		}
+	}
+	if len(data) > 0 {
+		err = p.StoreCursor(ctx, data[0], data[len(data)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (m *MongoMapper) Count(ctx context.Context, userId string) (int64, error) {
+	return m.conn.CountDocuments(ctx, bson.M{consts.UserId: userId})
+}
+
+func (m *MongoMapper) FindManyAndCount(ctx context.Context, userId string, popts *pagination.PaginationOptions, sorter mongop.MongoCursor) ([]*Reward, int64, error) {
+	var data []*Reward
+	var total int64
+	if err := mr.Finish(func() error {
+		var err error
+		data, err = m.FindMany(ctx, userId, popts, sorter)
+		return err
+	}, func() error {
+		var err error
+		total, err = m.Count(ctx, userId)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// SumAmount 统计用户自since起获得的小鱼干总数
+func (m *MongoMapper) SumAmount(ctx context.Context, userId string, since time.Time) (int64, error) {
+	var data []struct {
+		Total int64 `bson:"total"`
+	}
+	err := m.conn.Aggregate(ctx, &data, bson.A{
+		bson.M{"$match": bson.M{consts.UserId: userId, consts.CreateAt: bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{consts.ID: nil, "total": bson.M{"$sum": "$" + consts.Amount}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	return data[0].Total, nil
+}
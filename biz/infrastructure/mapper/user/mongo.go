@@ -28,6 +28,7 @@ type (
 		Delete(ctx context.Context, id string) error
 		UpsertUser(ctx context.Context, data *User) error
 		FindOneNoCache(ctx context.Context, id string) (*User, error)
+		SetTimezone(ctx context.Context, id primitive.ObjectID, timezone string) error
 	}
 
 	MongoMapper struct {
@@ -39,6 +40,7 @@ type (
 		AvatarUrl string             `bson:"avatarUrl,omitempty" json:"avatar_url,omitempty"`
 		Nickname  string             `bson:"nickname,omitempty" json:"nickname,omitempty"`
 		Motto     string             `bson:"motto,omitempty" json:"motto,omitempty"`
+		Timezone  string             `bson:"timezone,omitempty" json:"timezone,omitempty"`
 		UpdateAt  time.Time          `bson:"updateAt,omitempty" json:"updateAt,omitempty"`
 		CreateAt  time.Time          `bson:"createAt,omitempty" json:"createAt,omitempty"`
 		// 仅ES查询时使用
@@ -72,6 +74,9 @@ func (m *MongoMapper) UpsertUser(ctx context.Context, data *User) error {
 	if data.Motto != "" {
 		set[consts.Motto] = data.Motto
 	}
+	if data.Timezone != "" {
+		set[consts.Timezone] = data.Timezone
+	}
 
 	update := bson.M{
 		"$set": set,
@@ -88,6 +93,22 @@ func (m *MongoMapper) UpsertUser(ctx context.Context, data *User) error {
 	return err
 }
 
+// SetTimezone 设置用户计算奖励日期所用的时区，timezone为空时清除设置
+func (m *MongoMapper) SetTimezone(ctx context.Context, id primitive.ObjectID, timezone string) error {
+	key := prefixUserCacheKey + id.Hex()
+	update := bson.M{
+		"$set":         bson.M{consts.UpdateAt: time.Now()},
+		"$setOnInsert": bson.M{consts.CreateAt: time.Now()},
+	}
+	if timezone != "" {
+		update["$set"].(bson.M)[consts.Timezone] = timezone
+	} else {
+		update["$unset"] = bson.M{consts.Timezone: ""}
+	}
+	_, err := m.conn.UpdateOne(ctx, key, bson.M{consts.ID: id}, update, options.Update().SetUpsert(true))
+	return err
+}
+
 func (m *MongoMapper) Insert(ctx context.Context, data *User) error {
 	if data.ID.IsZero() {
 		data.ID = primitive.NewObjectID()
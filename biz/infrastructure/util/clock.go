@@ -0,0 +1,57 @@
+package util
+
+import (
+	"sync"
+	"time"
+	// 运行镜像中只有Asia/Shanghai的时区数据，内嵌完整时区库以支持其他时区
+	_ "time/tzdata"
+)
+
+// Clock 获取当前时间，测试时可注入固定的时钟
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func NewClock() Clock {
+	return systemClock{}
+}
+
+var locations sync.Map
+
+// LoadLocation 带缓存的time.LoadLocation，name为空时返回time.Local
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// DayRange 返回t在loc时区下所在自然日的起止时间
+func DayRange(t time.Time, loc *time.Location) (start, end time.Time) {
+	t = t.In(loc)
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	end = start.AddDate(0, 0, 1)
+	return
+}
+
+// WeekRange 返回t在loc时区下所在自然周的起止时间，每周从周一开始
+func WeekRange(t time.Time, loc *time.Location) (start, end time.Time) {
+	start, _ = DayRange(t, loc)
+	start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	end = start.AddDate(0, 0, 7)
+	return
+}
@@ -0,0 +1,148 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestDayRange(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name      string
+		t         time.Time
+		loc       *time.Location
+		wantStart time.Time
+		wantLen   time.Duration
+	}{
+		{
+			name:      "just before midnight",
+			t:         time.Date(2026, 10, 18, 23, 59, 59, 0, shanghai),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 18, 0, 0, 0, 0, shanghai),
+			wantLen:   24 * time.Hour,
+		},
+		{
+			name:      "exactly midnight",
+			t:         time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			wantLen:   24 * time.Hour,
+		},
+		{
+			name:      "utc time already next day in location",
+			t:         time.Date(2026, 10, 18, 16, 30, 0, 0, time.UTC),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			wantLen:   24 * time.Hour,
+		},
+		{
+			name:      "utc time still previous day in location",
+			t:         time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC),
+			loc:       newYork,
+			wantStart: time.Date(2026, 10, 18, 0, 0, 0, 0, newYork),
+			wantLen:   24 * time.Hour,
+		},
+		{
+			name:      "dst starts",
+			t:         time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			loc:       newYork,
+			wantStart: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			wantLen:   23 * time.Hour,
+		},
+		{
+			name:      "dst ends",
+			t:         time.Date(2026, 11, 1, 23, 30, 0, 0, newYork),
+			loc:       newYork,
+			wantStart: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			wantLen:   25 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := DayRange(tt.t, tt.loc)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("start = %v, want %v", start, tt.wantStart)
+			}
+			if got := end.Sub(start); got != tt.wantLen {
+				t.Errorf("day length = %v, want %v", got, tt.wantLen)
+			}
+			if tt.t.Before(start) || !tt.t.Before(end) {
+				t.Errorf("%v not in [%v, %v)", tt.t, start, end)
+			}
+		})
+	}
+}
+
+func TestWeekRange(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name      string
+		t         time.Time
+		loc       *time.Location
+		wantStart time.Time
+		wantLen   time.Duration
+	}{
+		{
+			name:      "sunday belongs to previous monday",
+			t:         time.Date(2026, 10, 18, 23, 59, 59, 0, shanghai),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 12, 0, 0, 0, 0, shanghai),
+			wantLen:   7 * 24 * time.Hour,
+		},
+		{
+			name:      "monday midnight starts a new week",
+			t:         time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			wantLen:   7 * 24 * time.Hour,
+		},
+		{
+			name:      "utc sunday is monday in location",
+			t:         time.Date(2026, 10, 18, 16, 30, 0, 0, time.UTC),
+			loc:       shanghai,
+			wantStart: time.Date(2026, 10, 19, 0, 0, 0, 0, shanghai),
+			wantLen:   7 * 24 * time.Hour,
+		},
+		{
+			name:      "week with dst start",
+			t:         time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			loc:       newYork,
+			wantStart: time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			wantLen:   7*24*time.Hour - time.Hour,
+		},
+		{
+			name:      "week with dst end",
+			t:         time.Date(2026, 10, 27, 8, 0, 0, 0, newYork),
+			loc:       newYork,
+			wantStart: time.Date(2026, 10, 26, 0, 0, 0, 0, newYork),
+			wantLen:   7*24*time.Hour + time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := WeekRange(tt.t, tt.loc)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("start = %v, want %v", start, tt.wantStart)
+			}
+			if start.Weekday() != time.Monday {
+				t.Errorf("start weekday = %v, want Monday", start.Weekday())
+			}
+			if got := end.Sub(start); got != tt.wantLen {
+				t.Errorf("week length = %v, want %v", got, tt.wantLen)
+			}
+		})
+	}
+}
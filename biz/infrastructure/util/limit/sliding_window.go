@@ -0,0 +1,52 @@
+package limit
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// slidingWindowScript 用有序集合记录窗口内的每次请求
+// KEYS[1] 限流key, ARGV[1] 当前毫秒时间戳, ARGV[2] 窗口毫秒数, ARGV[3] 窗口内允许的次数, ARGV[4] 本次请求的唯一标识
+// 返回 1 允许, 0 拒绝
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], 0, now - window)
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], now, ARGV[4])
+redis.call("PEXPIRE", KEYS[1], window)
+return 1
+`)
+
+// SlidingWindowLimiter 基于Redis的滑动窗口限流
+type SlidingWindowLimiter struct {
+	store     *redis.Redis
+	keyPrefix string
+	window    time.Duration
+	limit     int64
+}
+
+func NewSlidingWindowLimiter(store *redis.Redis, keyPrefix string, window time.Duration, limit int64) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		store:     store,
+		keyPrefix: keyPrefix,
+		window:    window,
+		limit:     limit,
+	}
+}
+
+// AllowCtx 记录一次请求并返回是否在限额内
+func (l *SlidingWindowLimiter) AllowCtx(ctx context.Context, key string) (bool, error) {
+	val, err := l.store.ScriptRunCtx(ctx, slidingWindowScript, []string{l.keyPrefix + key},
+		time.Now().UnixMilli(), l.window.Milliseconds(), l.limit, primitive.NewObjectID().Hex())
+	if err != nil {
+		return false, err
+	}
+	allowed, _ := val.(int64)
+	return allowed == 1, nil
+}
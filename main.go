@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"net"
+	"os"
 
 	"github.com/cloudwego/kitex/pkg/klog"
 	"github.com/cloudwego/kitex/pkg/rpcinfo"
@@ -15,12 +19,29 @@ import (
 	"github.com/xh-polaris/meowchat-user/provider"
 )
 
+var reconcile = flag.Bool("reconcile", false, "reconcile like counters once and exit")
+
 func main() {
+	flag.Parse()
 	klog.SetLogger(logx.NewKlogLogger())
 	s, err := provider.NewUserServerImpl()
 	if err != nil {
 		panic(err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *reconcile {
+		report, err := s.ReconcileService.ReconcileCounters(ctx)
+		if err != nil {
+			log.Error(err.Error())
+			cancel()
+			os.Exit(1)
+		}
+		fmt.Printf("scanned=%d corrected=%d skipped=%d cost=%s\n", report.Scanned, len(report.Corrections), report.Skipped, report.Cost)
+		return
+	}
+	s.Scheduler.Start(ctx)
+
 	addr, err := net.ResolveTCPAddr("tcp", s.ListenOn)
 	if err != nil {
 		panic(err)
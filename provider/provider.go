@@ -3,11 +3,17 @@ package provider
 import (
 	"github.com/google/wire"
 
+	"github.com/xh-polaris/meowchat-user/biz/application/job"
 	"github.com/xh-polaris/meowchat-user/biz/application/service"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/activity"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/counter"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/favorite"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/reward"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/user"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/stores/redis"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
 )
 
 var AllProvider = wire.NewSet(
@@ -18,16 +24,30 @@ var AllProvider = wire.NewSet(
 var ApplicationSet = wire.NewSet(
 	service.LikeSet,
 	service.UserSet,
+	service.RewardSet,
+	service.RelationSet,
+	service.TrendingSet,
+	service.LeaderboardSet,
+	service.ReconcileSet,
+	service.FavoriteSet,
+	service.ActivitySet,
+	job.SchedulerSet,
 )
 
 var InfrastructureSet = wire.NewSet(
 	config.NewConfig,
 	redis.NewRedis,
+	util.NewClock,
 	MapperSet,
 )
 
 var MapperSet = wire.NewSet(
 	like.NewMongoModel,
+	counter.NewMongoMapper,
+	reward.NewMongoMapper,
 	user.NewMongoMapper,
 	user.NewEsMapper,
+	favorite.NewFolderMongoMapper,
+	favorite.NewMongoMapper,
+	activity.NewMongoMapper,
 )
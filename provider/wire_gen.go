@@ -8,11 +8,17 @@ package provider
 
 import (
 	"github.com/xh-polaris/meowchat-user/biz/adaptor"
+	"github.com/xh-polaris/meowchat-user/biz/application/job"
 	"github.com/xh-polaris/meowchat-user/biz/application/service"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/config"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/activity"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/counter"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/favorite"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/like"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/reward"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/mapper/user"
 	"github.com/xh-polaris/meowchat-user/biz/infrastructure/stores/redis"
+	"github.com/xh-polaris/meowchat-user/biz/infrastructure/util"
 )
 
 // Injectors from wire.go:
@@ -23,23 +29,90 @@ func NewUserServerImpl() (*adaptor.UserServerImpl, error) {
 		return nil, err
 	}
 	iMongoMapper := like.NewMongoModel(configConfig)
+	counterIMongoMapper := counter.NewMongoMapper(configConfig)
 	redisRedis := redis.NewRedis(configConfig)
-	likeServiceImpl := &service.LikeServiceImpl{
+	rewardIMongoMapper := reward.NewMongoMapper(configConfig)
+	userIMongoMapper := user.NewMongoMapper(configConfig)
+	clock := util.NewClock()
+	configRewardPolicy := &service.ConfigRewardPolicy{
+		Config: configConfig,
+	}
+	rewardServiceImpl := &service.RewardServiceImpl{
+		Config:          configConfig,
+		RewardModel:     rewardIMongoMapper,
+		UserMongoMapper: userIMongoMapper,
+		Redis:           redisRedis,
+		Clock:           clock,
+		Policy:          configRewardPolicy,
+	}
+	leaderboardServiceImpl := &service.LeaderboardServiceImpl{
 		Config:    configConfig,
 		LikeModel: iMongoMapper,
 		Redis:     redisRedis,
+		Clock:     clock,
+	}
+	trendingServiceImpl := &service.TrendingServiceImpl{
+		Config:    configConfig,
+		LikeModel: iMongoMapper,
+		Redis:     redisRedis,
+		Clock:     clock,
+	}
+	activityIMongoMapper := activity.NewMongoMapper(configConfig)
+	activityServiceImpl := &service.ActivityServiceImpl{
+		ActivityModel: activityIMongoMapper,
+		LikeModel:     iMongoMapper,
+	}
+	likeServiceImpl := &service.LikeServiceImpl{
+		Config:             configConfig,
+		LikeModel:          iMongoMapper,
+		CounterModel:       counterIMongoMapper,
+		Redis:              redisRedis,
+		RewardService:      rewardServiceImpl,
+		LeaderboardService: leaderboardServiceImpl,
+		ActivityService:    activityServiceImpl,
+		TrendingService:    trendingServiceImpl,
 	}
-	userIMongoMapper := user.NewMongoMapper(configConfig)
 	iEsMapper := user.NewEsMapper(configConfig)
 	userServiceImpl := &service.UserServiceImpl{
 		Config:          configConfig,
 		UserMongoMapper: userIMongoMapper,
 		UserEsMapper:    iEsMapper,
+		ActivityService: activityServiceImpl,
 	}
-	userServerImpl := &adaptor.UserServerImpl{
-		Config:      configConfig,
+	relationServiceImpl := &service.RelationServiceImpl{
 		LikeService: likeServiceImpl,
-		UserService: userServiceImpl,
+		LikeModel:   iMongoMapper,
+	}
+	reconcileServiceImpl := &service.ReconcileServiceImpl{
+		Config:       configConfig,
+		LikeModel:    iMongoMapper,
+		CounterModel: counterIMongoMapper,
+	}
+	scheduler := &job.Scheduler{
+		Config:             configConfig,
+		Redis:              redisRedis,
+		TrendingService:    trendingServiceImpl,
+		LeaderboardService: leaderboardServiceImpl,
+		ReconcileService:   reconcileServiceImpl,
+	}
+	iFolderMongoMapper := favorite.NewFolderMongoMapper(configConfig)
+	favoriteIMongoMapper := favorite.NewMongoMapper(configConfig)
+	favoriteServiceImpl := &service.FavoriteServiceImpl{
+		FolderModel:   iFolderMongoMapper,
+		FavoriteModel: favoriteIMongoMapper,
+	}
+	userServerImpl := &adaptor.UserServerImpl{
+		Config:             configConfig,
+		LikeService:        likeServiceImpl,
+		UserService:        userServiceImpl,
+		RewardService:      rewardServiceImpl,
+		RelationService:    relationServiceImpl,
+		TrendingService:    trendingServiceImpl,
+		LeaderboardService: leaderboardServiceImpl,
+		ReconcileService:   reconcileServiceImpl,
+		FavoriteService:    favoriteServiceImpl,
+		ActivityService:    activityServiceImpl,
+		Scheduler:          scheduler,
 	}
 	return userServerImpl, nil
 }